@@ -0,0 +1,22 @@
+package osdp
+
+import "context"
+
+// Abort cancels whatever the PD is doing on the ACU's behalf, such as a biometric scan,
+// a prompt or a transparent card session
+func (osdpMessenger *OSDPMessenger) Abort(ctx context.Context, peripheralAddress byte) error {
+	reply, err := osdpMessenger.sendAndReceiveContext(ctx, CMD_ABORT, peripheralAddress, []byte{})
+	if err != nil {
+		return err
+	}
+	return expectReply(reply, REPLY_ACK)
+}
+
+// abortOnCancel sends osdp_ABORT when an operation failed because its context is done,
+// so the PD does not keep waiting for a finger or card nobody is going to read
+func (osdpMessenger *OSDPMessenger) abortOnCancel(ctx context.Context, peripheralAddress byte, err error) error {
+	if err != nil && ctx.Err() != nil {
+		osdpMessenger.Abort(context.Background(), peripheralAddress)
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package osdp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+)
+
+const minimumACUReceiveSize uint16 = 64
+
+// ACUReceiveSizeCommand tells the PD the largest reply the ACU can receive so it fragments larger replies
+type ACUReceiveSizeCommand struct {
+	ReceiveSize uint16
+}
+
+func (acuReceiveSizeCommand *ACUReceiveSizeCommand) Code() OSDPCode {
+	return CMD_ACURXSIZE
+}
+
+func (acuReceiveSizeCommand *ACUReceiveSizeCommand) Validate() error {
+	if acuReceiveSizeCommand.ReceiveSize < minimumACUReceiveSize {
+		return InvalidReceiveSizeError
+	}
+	return nil
+}
+
+func (acuReceiveSizeCommand *ACUReceiveSizeCommand) ToBytes() []byte {
+	msgData := make([]byte, 2)
+	binary.LittleEndian.PutUint16(msgData, acuReceiveSizeCommand.ReceiveSize)
+	return msgData
+}
+
+// SetACUReceiveSize sets the receive size announced to each PD when it comes online, 1024 by default and 0 to stop
+// the announcement. It is sent ahead of the next message to the PD, whether that comes from NextMessage or a typed
+// helper. A PD that NAKs it as an unknown command, as PDs older than OSDP 2.2 do, is not sent it again
+func (osdpMessenger *OSDPMessenger) SetACUReceiveSize(receiveSize uint16) error {
+	if receiveSize != 0 && receiveSize < minimumACUReceiveSize {
+		return InvalidReceiveSizeError
+	}
+	osdpMessenger.receiveSize = receiveSize
+	return nil
+}
+
+func (osdpMessenger *OSDPMessenger) SendACUReceiveSize(ctx context.Context, peripheralAddress byte, receiveSize uint16) error {
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, &ACUReceiveSizeCommand{ReceiveSize: receiveSize})
+	if err != nil {
+		return err
+	}
+	return expectReply(reply, REPLY_ACK)
+}
+
+// Online reports whether the PD answered its last message
+func (osdpMessenger *OSDPMessenger) Online(peripheralAddress byte) bool {
+	state, ok := osdpMessenger.peripherals[peripheralAddress]
+	return ok && state.online
+}
+
+// setOnline records a reply from the PD. A PD coming online is due the osdp_ACURXSIZE announcement
+func (osdpMessenger *OSDPMessenger) setOnline(peripheralAddress byte) {
+	state := osdpMessenger.peripheral(peripheralAddress)
+	if state.online {
+		return
+	}
+	state.online = true
+	state.announceReceiveSize = osdpMessenger.receiveSize != 0 && !state.receiveSizeUnsupported
+}
+
+// takeReceiveSizeAnnouncement returns the osdp_ACURXSIZE the PD is due, at most once per time it comes online
+func (osdpMessenger *OSDPMessenger) takeReceiveSizeAnnouncement(peripheralAddress byte) *ACUReceiveSizeCommand {
+	state, ok := osdpMessenger.peripherals[peripheralAddress]
+	if !ok || !state.announceReceiveSize {
+		return nil
+	}
+	state.announceReceiveSize = false
+	if osdpMessenger.receiveSize == 0 || state.receiveSizeUnsupported {
+		return nil
+	}
+	return &ACUReceiveSizeCommand{ReceiveSize: osdpMessenger.receiveSize}
+}
+
+// announceReceiveSize sends a due osdp_ACURXSIZE ahead of a typed helper's command. A NAK only means the
+// PD does not support it, so only a failure to reach the PD is returned
+func (osdpMessenger *OSDPMessenger) announceReceiveSize(ctx context.Context, peripheralAddress byte) error {
+	acuReceiveSizeCommand := osdpMessenger.takeReceiveSizeAnnouncement(peripheralAddress)
+	if acuReceiveSizeCommand == nil {
+		return nil
+	}
+	_, err := osdpMessenger.sendAndReceiveContext(ctx, CMD_ACURXSIZE, peripheralAddress, acuReceiveSizeCommand.ToBytes())
+	var nakError *NakError
+	if err != nil && !errors.As(err, &nakError) {
+		return err
+	}
+	return nil
+}
+
+// checkReceiveSizeReply stops announcing the receive size to a PD that does not know osdp_ACURXSIZE
+func (osdpMessenger *OSDPMessenger) checkReceiveSizeReply(osdpMessage *OSDPMessage, reply *OSDPMessage) {
+	if osdpMessage.MessageCode == CMD_ACURXSIZE && errors.Is(reply.NakError(), NakBadCommandError) {
+		osdpMessenger.peripheral(osdpMessage.PeripheralAddress).receiveSizeUnsupported = true
+	}
+}
+
+// setOffline records a PD that stopped answering. Whatever state it comes back in, it is restarted at sequence number 0
+func (osdpMessenger *OSDPMessenger) setOffline(peripheralAddress byte) {
+	osdpMessenger.peripheral(peripheralAddress).online = false
+	osdpMessenger.ResetSequenceNumber(peripheralAddress)
+}
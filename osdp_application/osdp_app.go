@@ -22,7 +22,7 @@ type (
 func StartCommunication(ctx context.Context, transceiver osdp.OSDPTransceiver, osdpHandler OSDPMessageHandler, outgoingMessageChan chan *osdp.OSDPMessage, errorHandler ErrorHandler, peripheralAddress byte) {
 	ticker := time.NewTicker(osdpMessageFrequencyMS * time.Millisecond)
 
-	osdpMessenger = osdp.NewOSDPMessenger(transceiver)
+	osdpMessenger = osdp.NewOSDPMessenger(transceiver, false)
 	executeOSDPCycle := func(outgoingMessage *osdp.OSDPMessage, writeTimeout time.Duration, readTimeout time.Duration) {
 		osdpResponse, err := osdpMessenger.SendAndReceive(outgoingMessage, writeTimeout, readTimeout)
 		if err != nil {
@@ -30,6 +30,9 @@ func StartCommunication(ctx context.Context, transceiver osdp.OSDPTransceiver, o
 			return
 		}
 		osdpHandler(osdpResponse)
+		for osdpReply := osdpMessenger.NextReply(peripheralAddress); osdpReply != nil; osdpReply = osdpMessenger.NextReply(peripheralAddress) {
+			osdpHandler(osdpReply)
+		}
 	}
 
 	for {
@@ -39,7 +42,11 @@ func StartCommunication(ctx context.Context, transceiver osdp.OSDPTransceiver, o
 		case outgoingMessage := <-outgoingMessageChan:
 			executeOSDPCycle(outgoingMessage, osdpMessageTimeout, osdpMessageTimeout)
 		case <-ticker.C:
-			osdpMessage := osdp.NewOSDPMessage(osdp.CMD_POLL, peripheralAddress, nil)
+			osdpMessage, err := osdpMessenger.NextMessage(peripheralAddress)
+			if err != nil {
+				errorHandler(err)
+				continue
+			}
 			executeOSDPCycle(osdpMessage, osdpMessageTimeout, osdpMessageTimeout)
 		}
 	}
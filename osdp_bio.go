@@ -0,0 +1,213 @@
+package osdp
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+)
+
+type BioType byte
+
+const (
+	BioTypeNotSpecified      BioType = 0x00
+	BioTypeRightThumb        BioType = 0x01
+	BioTypeRightIndex        BioType = 0x02
+	BioTypeRightMiddle       BioType = 0x03
+	BioTypeRightRing         BioType = 0x04
+	BioTypeRightLittle       BioType = 0x05
+	BioTypeLeftThumb         BioType = 0x06
+	BioTypeLeftIndex         BioType = 0x07
+	BioTypeLeftMiddle        BioType = 0x08
+	BioTypeLeftRing          BioType = 0x09
+	BioTypeLeftLittle        BioType = 0x0A
+	BioTypeRightIris         BioType = 0x0B
+	BioTypeRightRetina       BioType = 0x0C
+	BioTypeLeftIris          BioType = 0x0D
+	BioTypeLeftRetina        BioType = 0x0E
+	BioTypeFullFace          BioType = 0x0F
+	BioTypeRightHandGeometry BioType = 0x10
+	BioTypeLeftHandGeometry  BioType = 0x11
+)
+
+type BioFormat byte
+
+const (
+	BioFormatNotSpecified BioFormat = 0x00
+	BioFormatRawPGM       BioFormat = 0x01
+	BioFormatANSI378      BioFormat = 0x02
+)
+
+type BioStatus byte
+
+const (
+	BioStatusSuccess BioStatus = 0x00
+	BioStatusTimeout BioStatus = 0x01
+)
+
+const (
+	bioTemplateHeaderLength   int           = 6
+	bioMatchResultLength      int           = 3
+	osdpDefaultBioReadTimeout time.Duration = 10 * time.Second
+)
+
+// BioReadCommand asks the PD to scan a biometric and return its template, Quality is the minimum acceptable scan quality
+type BioReadCommand struct {
+	ReaderNumber byte
+	Type         BioType
+	Format       BioFormat
+	Quality      byte
+}
+
+// BioMatchCommand asks the PD to scan a biometric and compare it against Template
+type BioMatchCommand struct {
+	ReaderNumber     byte
+	Type             BioType
+	Format           BioFormat
+	QualityThreshold byte
+	Template         []byte
+}
+
+type BioReadResult struct {
+	ReaderNumber byte
+	Status       BioStatus
+	Type         BioType
+	Quality      byte
+	Template     []byte
+}
+
+type BioMatchResult struct {
+	ReaderNumber byte
+	Status       BioStatus
+	Score        byte
+}
+
+func (bioReadCommand *BioReadCommand) Code() OSDPCode {
+	return CMD_BIOREAD
+}
+
+func (bioReadCommand *BioReadCommand) Validate() error {
+	return nil
+}
+
+func (bioReadCommand *BioReadCommand) ValidateCapabilities(peripheralCapabilities *PeripheralCapabilities) error {
+	if !peripheralCapabilities.SupportsBiometrics() {
+		return CapabilityUnsupportedError
+	}
+	return nil
+}
+
+func (bioReadCommand *BioReadCommand) ToBytes() []byte {
+	return []byte{bioReadCommand.ReaderNumber, byte(bioReadCommand.Type), byte(bioReadCommand.Format), bioReadCommand.Quality}
+}
+
+func (bioMatchCommand *BioMatchCommand) Code() OSDPCode {
+	return CMD_BIOMATCH
+}
+
+func (bioMatchCommand *BioMatchCommand) Validate() error {
+	if len(bioMatchCommand.Template) == 0 || len(bioMatchCommand.Template) > 0xFFFF {
+		return InvalidBioTemplateError
+	}
+	return nil
+}
+
+func (bioMatchCommand *BioMatchCommand) ValidateCapabilities(peripheralCapabilities *PeripheralCapabilities) error {
+	if !peripheralCapabilities.SupportsBiometrics() {
+		return CapabilityUnsupportedError
+	}
+	return nil
+}
+
+func (bioMatchCommand *BioMatchCommand) ToBytes() []byte {
+	msgData := make([]byte, bioTemplateHeaderLength, bioTemplateHeaderLength+len(bioMatchCommand.Template))
+	msgData[0] = bioMatchCommand.ReaderNumber
+	msgData[1] = byte(bioMatchCommand.Type)
+	msgData[2] = byte(bioMatchCommand.Format)
+	msgData[3] = bioMatchCommand.QualityThreshold
+	binary.LittleEndian.PutUint16(msgData[4:6], uint16(len(bioMatchCommand.Template)))
+	return append(msgData, bioMatchCommand.Template...)
+}
+
+func ParseBioReadResult(msgData []byte) (*BioReadResult, error) {
+	if len(msgData) < bioTemplateHeaderLength {
+		return nil, ReplyDataLengthError
+	}
+	templateLength := int(binary.LittleEndian.Uint16(msgData[4:6]))
+	if len(msgData)-bioTemplateHeaderLength < templateLength {
+		return nil, ReplyDataLengthError
+	}
+	return &BioReadResult{
+		ReaderNumber: msgData[0],
+		Status:       BioStatus(msgData[1]),
+		Type:         BioType(msgData[2]),
+		Quality:      msgData[3],
+		Template:     msgData[bioTemplateHeaderLength : bioTemplateHeaderLength+templateLength],
+	}, nil
+}
+
+func ParseBioMatchResult(msgData []byte) (*BioMatchResult, error) {
+	if len(msgData) < bioMatchResultLength {
+		return nil, ReplyDataLengthError
+	}
+	return &BioMatchResult{ReaderNumber: msgData[0], Status: BioStatus(msgData[1]), Score: msgData[2]}, nil
+}
+
+// BioRead waits for the PD to scan a biometric, polling it until osdp_BIOREADR arrives or the context is done.
+// Without a deadline on the context the scan is given 10 seconds, a scan cut short by the context is aborted on the PD
+func (osdpMessenger *OSDPMessenger) BioRead(ctx context.Context, peripheralAddress byte, bioReadCommand *BioReadCommand) (*BioReadResult, error) {
+	ctx, cancel := withDefaultTimeout(ctx, osdpDefaultBioReadTimeout)
+	defer cancel()
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, bioReadCommand)
+	if err != nil {
+		return nil, err
+	}
+	msgData, err := osdpMessenger.awaitBioReply(ctx, peripheralAddress, reply, REPLY_BIOREADR)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBioReadResult(msgData)
+}
+
+// BioMatch sends a template for the PD to match against a fresh scan. Templates too large for the PD receive
+// buffer are sent as multi-part messages to PDs that report a largest combined message size
+func (osdpMessenger *OSDPMessenger) BioMatch(ctx context.Context, peripheralAddress byte, bioMatchCommand *BioMatchCommand) (*BioMatchResult, error) {
+	ctx, cancel := withDefaultTimeout(ctx, osdpDefaultBioReadTimeout)
+	defer cancel()
+	if err := osdpMessenger.validateCommand(peripheralAddress, bioMatchCommand); err != nil {
+		return nil, err
+	}
+	var reply *OSDPMessage
+	var err error
+	if osdpMessenger.needsMultiPart(peripheralAddress, len(bioMatchCommand.ToBytes())) {
+		reply, err = osdpMessenger.sendMultiPart(ctx, CMD_BIOMATCH, peripheralAddress, bioMatchCommand.ToBytes())
+	} else {
+		reply, err = osdpMessenger.SendCommand(ctx, peripheralAddress, bioMatchCommand)
+	}
+	if err != nil {
+		return nil, err
+	}
+	msgData, err := osdpMessenger.awaitBioReply(ctx, peripheralAddress, reply, REPLY_BIOMATCHR)
+	if err != nil {
+		return nil, err
+	}
+	return ParseBioMatchResult(msgData)
+}
+
+func (osdpMessenger *OSDPMessenger) awaitBioReply(ctx context.Context, peripheralAddress byte, reply *OSDPMessage, replyCode OSDPCode) ([]byte, error) {
+	var err error
+	if reply.MessageCode != replyCode {
+		if err := expectReply(reply, REPLY_ACK); err != nil {
+			return nil, err
+		}
+		reply, err = osdpMessenger.pollForReply(ctx, peripheralAddress, replyCode)
+		if err != nil {
+			return nil, osdpMessenger.abortOnCancel(ctx, peripheralAddress, err)
+		}
+	}
+	// PDs that accept multi-part messages still send a template that fits their reply as a single osdp_BIOREADR
+	if replyCode == REPLY_BIOREADR && osdpMessenger.multiPartEnabled(peripheralAddress) && isMultiPartFragment(reply.MessageData) {
+		msgData, err := osdpMessenger.receiveMultiPart(ctx, peripheralAddress, reply)
+		return msgData, osdpMessenger.abortOnCancel(ctx, peripheralAddress, err)
+	}
+	return reply.MessageData, nil
+}
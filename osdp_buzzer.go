@@ -0,0 +1,78 @@
+package osdp
+
+import "time"
+
+type BuzzerToneCode byte
+
+const (
+	BuzzerToneNone    BuzzerToneCode = 0x00 // No tone, deprecated in favour of BuzzerToneOff
+	BuzzerToneOff     BuzzerToneCode = 0x01
+	BuzzerToneDefault BuzzerToneCode = 0x02
+)
+
+const (
+	// On and off times are expressed in units of 100ms
+	buzzerTimeUnit    time.Duration = 100 * time.Millisecond
+	maxBuzzerDuration time.Duration = 0xFF * buzzerTimeUnit
+)
+
+// BuzzerCommand is a single osdp_BUZ record. A Count of 0 repeats until cancelled
+type BuzzerCommand struct {
+	ReaderNumber byte
+	ToneCode     BuzzerToneCode
+	OnTime       byte
+	OffTime      byte
+	Count        byte
+}
+
+func NewBuzzerCommand(readerNumber byte, onTime time.Duration, offTime time.Duration, count byte) (*BuzzerCommand, error) {
+	// Times are counted in 100ms by the PD, a non-zero time shorter than that would be sent as 0
+	if onTime < buzzerTimeUnit || onTime > maxBuzzerDuration || offTime < 0 || offTime > maxBuzzerDuration || (offTime > 0 && offTime < buzzerTimeUnit) {
+		return nil, BuzzerTimeOutOfRangeError
+	}
+	return &BuzzerCommand{
+		ReaderNumber: readerNumber,
+		ToneCode:     BuzzerToneDefault,
+		OnTime:       byte(onTime / buzzerTimeUnit),
+		OffTime:      byte(offTime / buzzerTimeUnit),
+		Count:        count,
+	}, nil
+}
+
+func (buzzerCommand *BuzzerCommand) Code() OSDPCode {
+	return CMD_BUZ
+}
+
+func (buzzerCommand *BuzzerCommand) Validate() error {
+	if buzzerCommand.ToneCode > BuzzerToneDefault {
+		return InvalidBuzzerToneError
+	}
+	if buzzerCommand.ToneCode == BuzzerToneDefault && buzzerCommand.OnTime == 0 {
+		return BuzzerTimeOutOfRangeError
+	}
+	return nil
+}
+
+func (buzzerCommand *BuzzerCommand) ToBytes() []byte {
+	return []byte{
+		buzzerCommand.ReaderNumber, byte(buzzerCommand.ToneCode),
+		buzzerCommand.OnTime, buzzerCommand.OffTime, buzzerCommand.Count,
+	}
+}
+
+func BuzzerOff(readerNumber byte) *BuzzerCommand {
+	return &BuzzerCommand{ReaderNumber: readerNumber, ToneCode: BuzzerToneOff}
+}
+
+func BuzzerAccessGranted(readerNumber byte) *BuzzerCommand {
+	return &BuzzerCommand{ReaderNumber: readerNumber, ToneCode: BuzzerToneDefault, OnTime: 2, OffTime: 0, Count: 1}
+}
+
+func BuzzerAccessDenied(readerNumber byte) *BuzzerCommand {
+	return &BuzzerCommand{ReaderNumber: readerNumber, ToneCode: BuzzerToneDefault, OnTime: 1, OffTime: 1, Count: 3}
+}
+
+// BuzzerDoorHeldAlarm sounds until cancelled with BuzzerOff
+func BuzzerDoorHeldAlarm(readerNumber byte) *BuzzerCommand {
+	return &BuzzerCommand{ReaderNumber: readerNumber, ToneCode: BuzzerToneDefault, OnTime: 5, OffTime: 5, Count: 0}
+}
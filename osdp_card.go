@@ -0,0 +1,276 @@
+package osdp
+
+import "encoding/binary"
+
+const rawCardDataHeaderLength int = 4
+
+// RawCardData is the osdp_RAW reply, Data holds BitCount bits packed most significant bit first
+type RawCardData struct {
+	ReaderNumber byte
+	FormatCode   OSDPCode
+	BitCount     uint16
+	Data         []byte
+}
+
+type CardCredential struct {
+	Format       string
+	FacilityCode uint64
+	CardNumber   uint64
+}
+
+// CardFormat decodes the bits of a raw card read and packs them for PD emulation. Formats are tried by bit count in a CardFormatRegistry
+type CardFormat interface {
+	Name() string
+	BitCount() int
+	Decode(bits []bool) (*CardCredential, error)
+	Encode(facilityCode uint64, cardNumber uint64) ([]bool, error)
+}
+
+func ParseRawCardData(msgData []byte) (*RawCardData, error) {
+	if len(msgData) < rawCardDataHeaderLength {
+		return nil, ReplyDataLengthError
+	}
+	bitCount := binary.LittleEndian.Uint16(msgData[2:4])
+	if len(msgData)-rawCardDataHeaderLength < (int(bitCount)+7)/8 {
+		return nil, ReplyDataLengthError
+	}
+	return &RawCardData{
+		ReaderNumber: msgData[0],
+		FormatCode:   OSDPCode(msgData[1]),
+		BitCount:     bitCount,
+		Data:         msgData[rawCardDataHeaderLength : rawCardDataHeaderLength+(int(bitCount)+7)/8],
+	}, nil
+}
+
+// NewRawCardData packs a credential in the given format as a PD would report it in osdp_RAW
+func NewRawCardData(readerNumber byte, cardFormat CardFormat, facilityCode uint64, cardNumber uint64) (*RawCardData, error) {
+	bits, err := cardFormat.Encode(facilityCode, cardNumber)
+	if err != nil {
+		return nil, err
+	}
+	return NewRawCardDataFromBits(readerNumber, WIEGAND_FORMAT_CODE, bits), nil
+}
+
+func NewRawCardDataFromBits(readerNumber byte, formatCode OSDPCode, bits []bool) *RawCardData {
+	data := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			data[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return &RawCardData{ReaderNumber: readerNumber, FormatCode: formatCode, BitCount: uint16(len(bits)), Data: data}
+}
+
+func (rawCardData *RawCardData) ToBytes() []byte {
+	msgData := make([]byte, rawCardDataHeaderLength, rawCardDataHeaderLength+len(rawCardData.Data))
+	msgData[0] = rawCardData.ReaderNumber
+	msgData[1] = byte(rawCardData.FormatCode)
+	binary.LittleEndian.PutUint16(msgData[2:4], rawCardData.BitCount)
+	return append(msgData, rawCardData.Data...)
+}
+
+func (rawCardData *RawCardData) Bits() []bool {
+	bits := make([]bool, rawCardData.BitCount)
+	for i := range bits {
+		bits[i] = rawCardData.Data[i/8]&(0x80>>uint(i%8)) != 0
+	}
+	return bits
+}
+
+type cardField struct {
+	start  int
+	length int
+}
+
+func (field cardField) decode(bits []bool) uint64 {
+	var value uint64
+	for i := field.start; i < field.start+field.length; i++ {
+		value <<= 1
+		if bits[i] {
+			value |= 1
+		}
+	}
+	return value
+}
+
+func (field cardField) encode(bits []bool, value uint64) error {
+	if field.length < 64 && value>>uint(field.length) != 0 {
+		return CardFieldOutOfRangeError
+	}
+	for i := field.start + field.length - 1; i >= field.start; i-- {
+		bits[i] = value&1 == 1
+		value >>= 1
+	}
+	return nil
+}
+
+// parityBit sits at position and makes the count of set bits over itself and covered even, or odd
+type parityBit struct {
+	position int
+	odd      bool
+	covered  []int
+}
+
+func (parity parityBit) valid(bits []bool) bool {
+	setBits := 0
+	if bits[parity.position] {
+		setBits++
+	}
+	for _, position := range parity.covered {
+		if bits[position] {
+			setBits++
+		}
+	}
+	return (setBits%2 == 1) == parity.odd
+}
+
+func (parity parityBit) set(bits []bool) {
+	bits[parity.position] = false
+	if !parity.valid(bits) {
+		bits[parity.position] = true
+	}
+}
+
+type wiegandFormat struct {
+	name         string
+	bitCount     int
+	facilityCode cardField
+	cardNumber   cardField
+	parityBits   []parityBit
+}
+
+func (format *wiegandFormat) Name() string {
+	return format.name
+}
+
+func (format *wiegandFormat) BitCount() int {
+	return format.bitCount
+}
+
+func (format *wiegandFormat) Decode(bits []bool) (*CardCredential, error) {
+	if len(bits) != format.bitCount {
+		return nil, UnknownCardFormatError
+	}
+	for _, parity := range format.parityBits {
+		if !parity.valid(bits) {
+			return nil, CardParityError
+		}
+	}
+	return &CardCredential{
+		Format:       format.name,
+		FacilityCode: format.facilityCode.decode(bits),
+		CardNumber:   format.cardNumber.decode(bits),
+	}, nil
+}
+
+func (format *wiegandFormat) Encode(facilityCode uint64, cardNumber uint64) ([]bool, error) {
+	bits := make([]bool, format.bitCount)
+	if err := format.facilityCode.encode(bits, facilityCode); err != nil {
+		return nil, err
+	}
+	if err := format.cardNumber.encode(bits, cardNumber); err != nil {
+		return nil, err
+	}
+	for _, parity := range format.parityBits {
+		parity.set(bits)
+	}
+	return bits, nil
+}
+
+func bitRange(first int, last int) []int {
+	positions := make([]int, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		positions = append(positions, i)
+	}
+	return positions
+}
+
+// corporate1000Positions selects the bits in [first, last] that the interleaved Corporate 1000 parity covers
+func corporate1000Positions(first int, last int, skip int) []int {
+	positions := []int{}
+	for i := first; i <= last; i++ {
+		if i%3 != skip {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+var (
+	CardFormatH10301 CardFormat = &wiegandFormat{
+		name: "H10301", bitCount: 26,
+		facilityCode: cardField{start: 1, length: 8}, cardNumber: cardField{start: 9, length: 16},
+		parityBits: []parityBit{
+			{position: 0, odd: false, covered: bitRange(1, 12)},
+			{position: 25, odd: true, covered: bitRange(13, 24)},
+		},
+	}
+	CardFormatH10306 CardFormat = &wiegandFormat{
+		name: "H10306", bitCount: 34,
+		facilityCode: cardField{start: 1, length: 16}, cardNumber: cardField{start: 17, length: 16},
+		parityBits: []parityBit{
+			{position: 0, odd: false, covered: bitRange(1, 16)},
+			{position: 33, odd: true, covered: bitRange(17, 32)},
+		},
+	}
+	CardFormatH10304 CardFormat = &wiegandFormat{
+		name: "H10304", bitCount: 37,
+		facilityCode: cardField{start: 1, length: 16}, cardNumber: cardField{start: 17, length: 19},
+		parityBits: []parityBit{
+			{position: 0, odd: false, covered: bitRange(1, 18)},
+			{position: 36, odd: true, covered: bitRange(18, 35)},
+		},
+	}
+	// Corporate 1000 parity bits are listed in the order they must be computed, the last one covers the others
+	CardFormatCorporate1000_35 CardFormat = &wiegandFormat{
+		name: "Corporate1000-35", bitCount: 35,
+		facilityCode: cardField{start: 2, length: 12}, cardNumber: cardField{start: 14, length: 20},
+		parityBits: []parityBit{
+			{position: 1, odd: false, covered: corporate1000Positions(2, 33, 1)},
+			{position: 34, odd: true, covered: corporate1000Positions(1, 33, 0)},
+			{position: 0, odd: true, covered: bitRange(1, 34)},
+		},
+	}
+	CardFormatCorporate1000_48 CardFormat = &wiegandFormat{
+		name: "Corporate1000-48", bitCount: 48,
+		facilityCode: cardField{start: 2, length: 22}, cardNumber: cardField{start: 24, length: 23},
+		parityBits: []parityBit{
+			{position: 1, odd: false, covered: corporate1000Positions(2, 46, 1)},
+			{position: 47, odd: true, covered: corporate1000Positions(1, 46, 0)},
+			{position: 0, odd: true, covered: bitRange(1, 47)},
+		},
+	}
+)
+
+type CardFormatRegistry struct {
+	formats []CardFormat
+}
+
+func NewCardFormatRegistry(cardFormats ...CardFormat) *CardFormatRegistry {
+	return &CardFormatRegistry{formats: cardFormats}
+}
+
+func NewStandardCardFormatRegistry() *CardFormatRegistry {
+	return NewCardFormatRegistry(CardFormatH10301, CardFormatH10306, CardFormatCorporate1000_35, CardFormatH10304, CardFormatCorporate1000_48)
+}
+
+// Register adds a format, formats registered first win when several share a bit count
+func (cardFormatRegistry *CardFormatRegistry) Register(cardFormat CardFormat) {
+	cardFormatRegistry.formats = append(cardFormatRegistry.formats, cardFormat)
+}
+
+func (cardFormatRegistry *CardFormatRegistry) Decode(rawCardData *RawCardData) (*CardCredential, error) {
+	bits := rawCardData.Bits()
+	err := UnknownCardFormatError
+	for _, cardFormat := range cardFormatRegistry.formats {
+		if cardFormat.BitCount() != len(bits) {
+			continue
+		}
+		cardCredential, decodeErr := cardFormat.Decode(bits)
+		if decodeErr == nil {
+			return cardCredential, nil
+		}
+		err = decodeErr
+	}
+	return nil, err
+}
@@ -0,0 +1,71 @@
+package osdp
+
+import "strconv"
+
+type ReadDirection byte
+
+const (
+	ReadForward ReadDirection = 0x00
+	ReadReverse ReadDirection = 0x01
+)
+
+const formattedCardDataHeaderLength int = 3
+
+// FormattedCardData is the osdp_FMT reply, card data already converted to ASCII by the reader
+type FormattedCardData struct {
+	ReaderNumber byte
+	Direction    ReadDirection
+	Data         string
+}
+
+// CardRead is a card presented to a reader, whether it was reported with osdp_RAW or osdp_FMT.
+// Credential is only set for raw data in a known format, CardNumber is set whenever the number is known
+type CardRead struct {
+	ReaderNumber byte
+	CardNumber   string
+	Credential   *CardCredential
+	Raw          *RawCardData
+	Formatted    *FormattedCardData
+}
+
+func ParseFormattedCardData(msgData []byte) (*FormattedCardData, error) {
+	if len(msgData) < formattedCardDataHeaderLength || len(msgData)-formattedCardDataHeaderLength < int(msgData[2]) {
+		return nil, ReplyDataLengthError
+	}
+	return &FormattedCardData{
+		ReaderNumber: msgData[0],
+		Direction:    ReadDirection(msgData[1]),
+		Data:         string(msgData[formattedCardDataHeaderLength : formattedCardDataHeaderLength+int(msgData[2])]),
+	}, nil
+}
+
+// ParseCardRead turns an osdp_RAW or osdp_FMT reply into a CardRead, decoding raw data with the registry if one is given
+func ParseCardRead(osdpMessage *OSDPMessage, cardFormatRegistry *CardFormatRegistry) (*CardRead, error) {
+	switch osdpMessage.MessageCode {
+	case REPLY_RAW:
+		rawCardData, err := ParseRawCardData(osdpMessage.MessageData)
+		if err != nil {
+			return nil, err
+		}
+		cardRead := &CardRead{ReaderNumber: rawCardData.ReaderNumber, Raw: rawCardData}
+		if cardFormatRegistry == nil {
+			return cardRead, nil
+		}
+		cardCredential, err := cardFormatRegistry.Decode(rawCardData)
+		if err == UnknownCardFormatError {
+			return cardRead, nil
+		} else if err != nil {
+			return nil, err
+		}
+		cardRead.Credential = cardCredential
+		cardRead.CardNumber = strconv.FormatUint(cardCredential.CardNumber, 10)
+		return cardRead, nil
+	case REPLY_FMT:
+		formattedCardData, err := ParseFormattedCardData(osdpMessage.MessageData)
+		if err != nil {
+			return nil, err
+		}
+		return &CardRead{ReaderNumber: formattedCardData.ReaderNumber, CardNumber: formattedCardData.Data, Formatted: formattedCardData}, nil
+	}
+	return nil, UnexpectedReplyError
+}
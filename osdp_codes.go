@@ -1,31 +1,40 @@
 package osdp
 
+import "fmt"
+
 type OSDPCode byte
 
 const (
-	CMD_POLL     OSDPCode = 0x60
-	CMD_ID       OSDPCode = 0x61
-	CMD_CAP      OSDPCode = 0x62
-	CMD_DIAG     OSDPCode = 0x63
-	CMD_LSTAT    OSDPCode = 0x64
-	CMD_ISTAT    OSDPCode = 0x65
-	CMD_OSTAT    OSDPCode = 0x66
-	CMD_RSTAT    OSDPCode = 0x67
-	CMD_OUT      OSDPCode = 0x68
-	CMD_LED      OSDPCode = 0x69
-	CMD_BUZ      OSDPCode = 0x6A
-	CMD_TEXT     OSDPCode = 0x6B
-	CMD_COMSET   OSDPCode = 0x6E
-	CMD_DATA     OSDPCode = 0x6F
-	CMD_PROMPT   OSDPCode = 0x71
-	CMD_BIOREAD  OSDPCode = 0x73
-	CMD_BIOMATCH OSDPCode = 0x74
-	CMD_KEYSET   OSDPCode = 0x75
-	CMD_CHLNG    OSDPCode = 0x76
-	CMD_SCRYPT   OSDPCode = 0x77
-	CMD_ABORT    OSDPCode = 0x7A
-	CMD_MAXREPLY OSDPCode = 0x7B
-	CMD_MFG      OSDPCode = 0x80
+	CMD_POLL         OSDPCode = 0x60
+	CMD_ID           OSDPCode = 0x61
+	CMD_CAP          OSDPCode = 0x62
+	CMD_DIAG         OSDPCode = 0x63
+	CMD_LSTAT        OSDPCode = 0x64
+	CMD_ISTAT        OSDPCode = 0x65
+	CMD_OSTAT        OSDPCode = 0x66
+	CMD_RSTAT        OSDPCode = 0x67
+	CMD_OUT          OSDPCode = 0x68
+	CMD_LED          OSDPCode = 0x69
+	CMD_BUZ          OSDPCode = 0x6A
+	CMD_TEXT         OSDPCode = 0x6B
+	CMD_COMSET       OSDPCode = 0x6E
+	CMD_DATA         OSDPCode = 0x6F
+	CMD_PROMPT       OSDPCode = 0x71
+	CMD_BIOREAD      OSDPCode = 0x73
+	CMD_BIOMATCH     OSDPCode = 0x74
+	CMD_KEYSET       OSDPCode = 0x75
+	CMD_CHLNG        OSDPCode = 0x76
+	CMD_SCRYPT       OSDPCode = 0x77
+	CMD_ACURXSIZE    OSDPCode = 0x7B
+	CMD_MAXREPLY     OSDPCode = CMD_ACURXSIZE
+	CMD_FILETRANSFER OSDPCode = 0x7C
+	CMD_MFG          OSDPCode = 0x80
+	CMD_XWR          OSDPCode = 0xA1
+	CMD_ABORT        OSDPCode = 0xA2
+	CMD_PIVDATA      OSDPCode = 0xA3
+	CMD_GENAUTH      OSDPCode = 0xA4
+	CMD_CRAUTH       OSDPCode = 0xA5
+	CMD_KEEPACTIVE   OSDPCode = 0xA7
 )
 
 const (
@@ -34,7 +43,8 @@ const (
 	REPLY_PDID      OSDPCode = 0x45
 	REPLY_PDCAP     OSDPCode = 0x46
 	REPLY_LSTATR    OSDPCode = 0x48
-	REPLY_IASTR     OSDPCode = 0x49
+	REPLY_ISTATR    OSDPCode = 0x49
+	REPLY_IASTR     OSDPCode = REPLY_ISTATR
 	REPLY_OSTATR    OSDPCode = 0x4A
 	REPLY_RSTATR    OSDPCode = 0x4B
 	REPLY_RAW       OSDPCode = 0x50
@@ -45,13 +55,20 @@ const (
 	REPLY_BIOMATCHR OSDPCode = 0x58
 	REPLY_CCRYPT    OSDPCode = 0x76
 	REPLY_RMAC_I    OSDPCode = 0x78
-	REPLY_MFGREP    OSDPCode = 0x90
 	REPLY_BUSY      OSDPCode = 0x79
+	REPLY_FTSTAT    OSDPCode = 0x7A
+	REPLY_PIVDATAR  OSDPCode = 0x80
+	REPLY_GENAUTHR  OSDPCode = 0x81
+	REPLY_CRAUTHR   OSDPCode = 0x82
+	REPLY_MFGSTATR  OSDPCode = 0x83
+	REPLY_MFGERRR   OSDPCode = 0x84
+	REPLY_MFGREP    OSDPCode = 0x90
 	REPLY_XRD       OSDPCode = 0xB1
 )
 
 const (
-	WIEGAND_FORMAT_CODE OSDPCode = 0x01
+	UNSPECIFIED_FORMAT_CODE OSDPCode = 0x00
+	WIEGAND_FORMAT_CODE     OSDPCode = 0x01
 )
 
 const (
@@ -66,6 +83,7 @@ const (
 )
 
 const (
+	ERR_NONE                      = 0x00
 	ERR_BAD_CRC                   = 0x01
 	ERR_BAD_LEN                   = 0x02
 	ERR_BAD_CMD                   = 0x03
@@ -75,4 +93,165 @@ const (
 	ERR_UNSUPPORTED_BIO_TYPE      = 0x07
 	ERR_UNSUPPORTED_BIO_FORMAT    = 0x08
 	ERR_UNKNOWN                   = 0x09
+	ERR_UNABLE_TO_PROCESS         = ERR_UNKNOWN
 )
+
+var commandNames = map[OSDPCode]string{
+	CMD_POLL:         "osdp_POLL",
+	CMD_ID:           "osdp_ID",
+	CMD_CAP:          "osdp_CAP",
+	CMD_DIAG:         "osdp_DIAG",
+	CMD_LSTAT:        "osdp_LSTAT",
+	CMD_ISTAT:        "osdp_ISTAT",
+	CMD_OSTAT:        "osdp_OSTAT",
+	CMD_RSTAT:        "osdp_RSTAT",
+	CMD_OUT:          "osdp_OUT",
+	CMD_LED:          "osdp_LED",
+	CMD_BUZ:          "osdp_BUZ",
+	CMD_TEXT:         "osdp_TEXT",
+	CMD_COMSET:       "osdp_COMSET",
+	CMD_DATA:         "osdp_DATA",
+	CMD_PROMPT:       "osdp_PROMPT",
+	CMD_BIOREAD:      "osdp_BIOREAD",
+	CMD_BIOMATCH:     "osdp_BIOMATCH",
+	CMD_KEYSET:       "osdp_KEYSET",
+	CMD_CHLNG:        "osdp_CHLNG",
+	CMD_SCRYPT:       "osdp_SCRYPT",
+	CMD_ACURXSIZE:    "osdp_ACURXSIZE",
+	CMD_FILETRANSFER: "osdp_FILETRANSFER",
+	CMD_MFG:          "osdp_MFG",
+	CMD_XWR:          "osdp_XWR",
+	CMD_ABORT:        "osdp_ABORT",
+	CMD_PIVDATA:      "osdp_PIVDATA",
+	CMD_GENAUTH:      "osdp_GENAUTH",
+	CMD_CRAUTH:       "osdp_CRAUTH",
+	CMD_KEEPACTIVE:   "osdp_KEEPACTIVE",
+}
+
+var replyNames = map[OSDPCode]string{
+	REPLY_ACK:       "osdp_ACK",
+	REPLY_NAK:       "osdp_NAK",
+	REPLY_PDID:      "osdp_PDID",
+	REPLY_PDCAP:     "osdp_PDCAP",
+	REPLY_LSTATR:    "osdp_LSTATR",
+	REPLY_ISTATR:    "osdp_ISTATR",
+	REPLY_OSTATR:    "osdp_OSTATR",
+	REPLY_RSTATR:    "osdp_RSTATR",
+	REPLY_RAW:       "osdp_RAW",
+	REPLY_FMT:       "osdp_FMT",
+	REPLY_KEYPAD:    "osdp_KEYPAD",
+	REPLY_COM:       "osdp_COM",
+	REPLY_BIOREADR:  "osdp_BIOREADR",
+	REPLY_BIOMATCHR: "osdp_BIOMATCHR",
+	REPLY_CCRYPT:    "osdp_CCRYPT",
+	REPLY_RMAC_I:    "osdp_RMAC_I",
+	REPLY_BUSY:      "osdp_BUSY",
+	REPLY_FTSTAT:    "osdp_FTSTAT",
+	REPLY_PIVDATAR:  "osdp_PIVDATAR",
+	REPLY_GENAUTHR:  "osdp_GENAUTHR",
+	REPLY_CRAUTHR:   "osdp_CRAUTHR",
+	REPLY_MFGSTATR:  "osdp_MFGSTATR",
+	REPLY_MFGERRR:   "osdp_MFGERRR",
+	REPLY_MFGREP:    "osdp_MFGREP",
+	REPLY_XRD:       "osdp_XRD",
+}
+
+// Replies a PD may send to osdp_POLL, everything it can report without being asked
+var pollReplies = []OSDPCode{
+	REPLY_ACK, REPLY_LSTATR, REPLY_ISTATR, REPLY_OSTATR, REPLY_RSTATR, REPLY_RAW, REPLY_FMT, REPLY_KEYPAD,
+	REPLY_BIOREADR, REPLY_BIOMATCHR, REPLY_PIVDATAR, REPLY_GENAUTHR, REPLY_CRAUTHR,
+	REPLY_MFGREP, REPLY_MFGSTATR, REPLY_MFGERRR, REPLY_XRD,
+}
+
+// validReplies lists the replies to each command besides osdp_NAK and osdp_BUSY, which any command may get
+var validReplies = map[OSDPCode][]OSDPCode{
+	CMD_POLL:         pollReplies,
+	CMD_ID:           {REPLY_PDID},
+	CMD_CAP:          {REPLY_PDCAP},
+	CMD_DIAG:         {REPLY_ACK, REPLY_MFGREP},
+	CMD_LSTAT:        {REPLY_LSTATR},
+	CMD_ISTAT:        {REPLY_ISTATR},
+	CMD_OSTAT:        {REPLY_OSTATR},
+	CMD_RSTAT:        {REPLY_RSTATR},
+	CMD_OUT:          {REPLY_ACK, REPLY_OSTATR},
+	CMD_LED:          {REPLY_ACK},
+	CMD_BUZ:          {REPLY_ACK},
+	CMD_TEXT:         {REPLY_ACK},
+	CMD_COMSET:       {REPLY_COM},
+	CMD_DATA:         {REPLY_ACK},
+	CMD_PROMPT:       {REPLY_ACK},
+	CMD_BIOREAD:      {REPLY_ACK, REPLY_BIOREADR},
+	CMD_BIOMATCH:     {REPLY_ACK, REPLY_BIOMATCHR},
+	CMD_KEYSET:       {REPLY_ACK},
+	CMD_CHLNG:        {REPLY_CCRYPT},
+	CMD_SCRYPT:       {REPLY_RMAC_I},
+	CMD_ACURXSIZE:    {REPLY_ACK},
+	CMD_FILETRANSFER: {REPLY_FTSTAT},
+	CMD_MFG:          {REPLY_ACK, REPLY_MFGREP, REPLY_MFGSTATR, REPLY_MFGERRR},
+	CMD_XWR:          {REPLY_ACK, REPLY_XRD},
+	CMD_ABORT:        {REPLY_ACK},
+	CMD_PIVDATA:      {REPLY_ACK, REPLY_PIVDATAR},
+	CMD_GENAUTH:      {REPLY_ACK, REPLY_GENAUTHR},
+	CMD_CRAUTH:       {REPLY_ACK, REPLY_CRAUTHR},
+	CMD_KEEPACTIVE:   {REPLY_ACK},
+}
+
+func (osdpCode OSDPCode) IsCommand() bool {
+	_, ok := commandNames[osdpCode]
+	return ok
+}
+
+func (osdpCode OSDPCode) IsReply() bool {
+	_, ok := replyNames[osdpCode]
+	return ok
+}
+
+// String names the code. Some values are both a command and a reply (osdp_CHLNG and osdp_CCRYPT are both 0x76),
+// those are named as both, use CommandString or ReplyString when the direction is known
+func (osdpCode OSDPCode) String() string {
+	commandName, isCommand := commandNames[osdpCode]
+	replyName, isReply := replyNames[osdpCode]
+	switch {
+	case isCommand && isReply:
+		return commandName + "/" + replyName
+	case isCommand:
+		return commandName
+	case isReply:
+		return replyName
+	}
+	return fmt.Sprintf("OSDPCode(0x%02X)", byte(osdpCode))
+}
+
+func (osdpCode OSDPCode) CommandString() string {
+	if commandName, ok := commandNames[osdpCode]; ok {
+		return commandName
+	}
+	return fmt.Sprintf("OSDPCode(0x%02X)", byte(osdpCode))
+}
+
+func (osdpCode OSDPCode) ReplyString() string {
+	if replyName, ok := replyNames[osdpCode]; ok {
+		return replyName
+	}
+	return fmt.Sprintf("OSDPCode(0x%02X)", byte(osdpCode))
+}
+
+// ValidReplies lists the replies a PD may send to command, excluding osdp_NAK and osdp_BUSY
+func ValidReplies(command OSDPCode) []OSDPCode {
+	return validReplies[command]
+}
+
+func IsValidReply(command OSDPCode, reply OSDPCode) bool {
+	if !command.IsCommand() {
+		return false
+	}
+	if reply == REPLY_NAK || reply == REPLY_BUSY {
+		return true
+	}
+	for _, validReply := range validReplies[command] {
+		if validReply == reply {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,15 @@
+package osdp
+
+// OSDPCommand is a typed command that can be encoded into the data block of an OSDP message
+type OSDPCommand interface {
+	Code() OSDPCode
+	Validate() error
+	ToBytes() []byte
+}
+
+func NewCommandMessage(peripheralAddress byte, sequenceNumber byte, osdpCommand OSDPCommand) (*OSDPMessage, error) {
+	if err := osdpCommand.Validate(); err != nil {
+		return nil, err
+	}
+	return NewOSDPMessage(osdpCommand.Code(), peripheralAddress, sequenceNumber, osdpCommand.ToBytes())
+}
@@ -0,0 +1,124 @@
+package osdp
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+)
+
+const communicationSettingsLength int = 5
+
+var validBaudRates = []uint32{9600, 19200, 38400, 57600, 115200, 230400}
+
+type CommunicationSettings struct {
+	PeripheralAddress byte
+	BaudRate          uint32
+}
+
+// ComSetRollbackError is returned when the PD did not answer at its new settings and the transceiver could not be
+// restored to its old baud rate either. It matches ComSetNotConfirmedError, Err is the transceiver failure
+type ComSetRollbackError struct {
+	Err error
+}
+
+func (comSetRollbackError *ComSetRollbackError) Error() string {
+	return fmt.Sprintf("%v, restoring the Baud Rate failed: %v", ComSetNotConfirmedError, comSetRollbackError.Err)
+}
+
+func (comSetRollbackError *ComSetRollbackError) Is(target error) bool {
+	return target == ComSetNotConfirmedError
+}
+
+func (comSetRollbackError *ComSetRollbackError) Unwrap() error {
+	return comSetRollbackError.Err
+}
+
+func (communicationSettings *CommunicationSettings) Code() OSDPCode {
+	return CMD_COMSET
+}
+
+func (communicationSettings *CommunicationSettings) Validate() error {
+	// 0x7F is reserved for broadcast and cannot be assigned to a PD
+	if communicationSettings.PeripheralAddress >= maxPeripheralAddress {
+		return AddressOutOfRangeError
+	}
+	for _, baudRate := range validBaudRates {
+		if communicationSettings.BaudRate == baudRate {
+			return nil
+		}
+	}
+	return InvalidBaudRateError
+}
+
+func (communicationSettings *CommunicationSettings) ToBytes() []byte {
+	msgData := make([]byte, communicationSettingsLength)
+	msgData[0] = communicationSettings.PeripheralAddress
+	binary.LittleEndian.PutUint32(msgData[1:], communicationSettings.BaudRate)
+	return msgData
+}
+
+// ParseCommunicationSettings decodes osdp_COM, the settings the PD will use after an osdp_COMSET
+func ParseCommunicationSettings(msgData []byte) (*CommunicationSettings, error) {
+	if len(msgData) != communicationSettingsLength {
+		return nil, ReplyDataLengthError
+	}
+	return &CommunicationSettings{PeripheralAddress: msgData[0], BaudRate: binary.LittleEndian.Uint32(msgData[1:])}, nil
+}
+
+// ChangeCommunicationSettings sends osdp_COMSET, switches the transceiver to the settings reported in osdp_COM
+// and polls the PD at its new address. If the PD does not answer, the old settings are restored; a transceiver
+// left at the new baud rate is reported as a *ComSetRollbackError.
+func (osdpMessenger *OSDPMessenger) ChangeCommunicationSettings(ctx context.Context, peripheralAddress byte, communicationSettings *CommunicationSettings) (*CommunicationSettings, error) {
+	if err := communicationSettings.Validate(); err != nil {
+		return nil, err
+	}
+	// Transceivers that cannot change their line speed can only follow an address change
+	baudRateTransceiver, canSetBaudRate := osdpMessenger.transceiver.(OSDPBaudRateTransceiver)
+	var oldBaudRate uint32
+	if canSetBaudRate {
+		oldBaudRate = baudRateTransceiver.BaudRate()
+	}
+
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, communicationSettings)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectReply(reply, REPLY_COM); err != nil {
+		return nil, err
+	}
+	newSettings, err := ParseCommunicationSettings(reply.MessageData)
+	if err != nil {
+		return nil, err
+	}
+
+	baudRateChanged := canSetBaudRate && newSettings.BaudRate != oldBaudRate
+	if baudRateChanged {
+		if err := baudRateTransceiver.SetBaudRate(newSettings.BaudRate); err != nil {
+			return nil, err
+		}
+	}
+	// The PD restarts its sequence numbers at its new settings
+	oldState := osdpMessenger.peripheral(peripheralAddress)
+	osdpMessenger.peripherals[newSettings.PeripheralAddress] = &peripheralState{capabilities: oldState.capabilities, online: oldState.online}
+	if _, err := osdpMessenger.sendAndReceiveContext(ctx, CMD_POLL, newSettings.PeripheralAddress, []byte{}); err != nil {
+		if newSettings.PeripheralAddress != peripheralAddress {
+			delete(osdpMessenger.peripherals, newSettings.PeripheralAddress)
+		}
+		osdpMessenger.ResetSequenceNumber(peripheralAddress)
+		if baudRateChanged {
+			if err := baudRateTransceiver.SetBaudRate(oldBaudRate); err != nil {
+				return nil, &ComSetRollbackError{Err: err}
+			}
+		}
+		return nil, ComSetNotConfirmedError
+	}
+
+	if newSettings.PeripheralAddress != peripheralAddress {
+		if queue, ok := osdpMessenger.commandQueue[peripheralAddress]; ok {
+			osdpMessenger.commandQueue[newSettings.PeripheralAddress] = queue
+			delete(osdpMessenger.commandQueue, peripheralAddress)
+		}
+		delete(osdpMessenger.peripherals, peripheralAddress)
+	}
+	return newSettings, nil
+}
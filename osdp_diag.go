@@ -0,0 +1,41 @@
+package osdp
+
+import "context"
+
+type DiagnosticTest byte
+
+// Test codes beyond DiagnosticSelfTest are defined by the PD manufacturer
+const (
+	DiagnosticSelfTest DiagnosticTest = 0x00
+)
+
+// DiagnosticCommand asks the PD to run a diagnostic, Data is passed to tests that take parameters
+type DiagnosticCommand struct {
+	Test DiagnosticTest
+	Data []byte
+}
+
+func (diagnosticCommand *DiagnosticCommand) Code() OSDPCode {
+	return CMD_DIAG
+}
+
+func (diagnosticCommand *DiagnosticCommand) Validate() error {
+	return nil
+}
+
+func (diagnosticCommand *DiagnosticCommand) ToBytes() []byte {
+	return append([]byte{byte(diagnosticCommand.Test)}, diagnosticCommand.Data...)
+}
+
+// RunDiagnostic runs a diagnostic on the PD, a failed test is reported by the PD as an osdp_NAK and returned as its NakError.
+// PDs that report more than pass or fail answer with an osdp_MFGREP, which is returned; a plain osdp_ACK returns nil
+func (osdpMessenger *OSDPMessenger) RunDiagnostic(ctx context.Context, peripheralAddress byte, diagnosticCommand *DiagnosticCommand) (*ManufacturerReply, error) {
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, diagnosticCommand)
+	if err != nil {
+		return nil, err
+	}
+	if reply.MessageCode == REPLY_MFGREP {
+		return ParseManufacturerReply(reply.MessageData)
+	}
+	return nil, expectReply(reply, REPLY_ACK)
+}
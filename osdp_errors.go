@@ -12,4 +12,41 @@ var (
 	InvalidSequenceNumber       = errors.New("Invalid Sequence Number")
 	IncorrectRandomNumberLength = errors.New("Invalid Random Number byte array length")
 	InvalidSecureBlockType      = errors.New("Invalid Secure Block Type")
+	EmptyCommandError           = errors.New("Command has no records")
+	InvalidLEDControlCodeError  = errors.New("Invalid LED Control Code")
+	InvalidLEDColorError        = errors.New("Invalid LED Color")
+	LEDTimeOutOfRangeError      = errors.New("LED Time Out of Range")
+	InvalidBuzzerToneError      = errors.New("Invalid Buzzer Tone Code")
+	BuzzerTimeOutOfRangeError   = errors.New("Buzzer Time Out of Range")
+	InvalidTextCommandError     = errors.New("Invalid Text Command Code")
+	InvalidTextPositionError    = errors.New("Text Position Out of Range")
+	InvalidTextCharacterError   = errors.New("Text contains non printable ASCII characters")
+	TextTooLongError            = errors.New("Text too long for display")
+	UnexpectedReplyError        = errors.New("Unexpected OSDP Reply")
+	InvalidOutputControlError   = errors.New("Invalid Output Control Code")
+	OutputTimerOutOfRangeError  = errors.New("Output Timer Out of Range")
+	OutputStateMismatchError    = errors.New("Output not in expected state")
+	ReplyDataLengthError        = errors.New("Reply Data Length Invalid")
+	InvalidBaudRateError        = errors.New("Invalid Baud Rate")
+	ComSetNotConfirmedError     = errors.New("Peripheral did not respond at new Communication Settings")
+	CapabilityUnsupportedError  = errors.New("Peripheral does not report the required capability")
+	InvalidStatusRequestError   = errors.New("Invalid Status Request")
+	UnknownCardFormatError      = errors.New("Unknown Card Format")
+	CardParityError             = errors.New("Card Parity Check Failed")
+	CardFieldOutOfRangeError    = errors.New("Card Field Value too large for Format")
+	MultiPartTooLargeError      = errors.New("Message too large for Multi-Part transfer")
+	MultiPartSequenceError      = errors.New("Multi-Part fragment out of sequence")
+	InvalidBioTemplateError     = errors.New("Invalid Biometric Template")
+	UnknownMfgCommandError      = errors.New("No codec registered for Manufacturer Command")
+	MfgCommandRegisteredError   = errors.New("Manufacturer Command already registered")
+	InvalidAPDUError            = errors.New("Invalid Smart Card APDU")
+	InvalidPIVObjectError       = errors.New("Invalid PIV Object ID")
+	InvalidPIVAuthDataError     = errors.New("PIV Authentication Data is empty")
+	UnsupportedPIVKeyError      = errors.New("Unsupported PIV Card Key Algorithm")
+	CardAuthenticationError     = errors.New("Card failed Challenge Response Authentication")
+	FileTransferRangeError      = errors.New("File Transfer Offset out of Range")
+	FileTransferRejectedError   = errors.New("Peripheral rejected File Transfer")
+	KeepActiveOutOfRangeError   = errors.New("Keep Active Time Out of Range")
+	InvalidReceiveSizeError     = errors.New("ACU Receive Size too small")
+	SequenceMismatchError       = errors.New("Reply Sequence Number does not match Command")
 )
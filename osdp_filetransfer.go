@@ -0,0 +1,225 @@
+package osdp
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+)
+
+type FileTransferType byte
+
+const (
+	FileTransferOpaque FileTransferType = 0x01
+)
+
+type FileTransferStatus int16
+
+const (
+	FileTransferProceed      FileTransferStatus = 0
+	FileTransferFinished     FileTransferStatus = 1
+	FileTransferRebooting    FileTransferStatus = 2
+	FileTransferFinishing    FileTransferStatus = 3
+	FileTransferAborted      FileTransferStatus = -1
+	FileTransferUnrecognized FileTransferStatus = -2
+	FileTransferMalformed    FileTransferStatus = -3
+)
+
+const (
+	FileTransferActionInterleave         byte = 0x01
+	FileTransferActionLeaveSecureChannel byte = 0x02
+	FileTransferActionPollResponse       byte = 0x04
+)
+
+const (
+	fileTransferHeaderLength int = 11
+	fileTransferStatusLength int = 7
+)
+
+// FileTransferCommand is one osdp_FILETRANSFER fragment, an empty Data is the idle message sent while the PD finishes
+type FileTransferCommand struct {
+	Type      FileTransferType
+	TotalSize uint32
+	Offset    uint32
+	Data      []byte
+}
+
+// FileTransferStatusReply is the osdp_FTSTAT sent by the PD after every fragment. UpdateMessageMax
+// is the fragment size the PD wants from now on, 0 leaves it unchanged
+type FileTransferStatusReply struct {
+	Action           byte
+	Delay            time.Duration
+	Status           FileTransferStatus
+	UpdateMessageMax uint16
+}
+
+type FileTransferProgress func(transferred int, total int)
+
+// FileTransfer sends a file to a PD one fragment per Step, so it can run inside the poll loop
+// alongside the other PDs on the bus
+type FileTransfer struct {
+	peripheralAddress byte
+	fileType          FileTransferType
+	data              []byte
+	offset            int
+	fragmentLength    int
+	nextStep          time.Time
+	finishing         bool
+	done              bool
+	lastStatus        *FileTransferStatusReply
+	progress          FileTransferProgress
+}
+
+func (fileTransferCommand *FileTransferCommand) Code() OSDPCode {
+	return CMD_FILETRANSFER
+}
+
+func (fileTransferCommand *FileTransferCommand) Validate() error {
+	if uint64(fileTransferCommand.Offset)+uint64(len(fileTransferCommand.Data)) > uint64(fileTransferCommand.TotalSize) || len(fileTransferCommand.Data) > 0xFFFF {
+		return FileTransferRangeError
+	}
+	return nil
+}
+
+func (fileTransferCommand *FileTransferCommand) ToBytes() []byte {
+	msgData := make([]byte, fileTransferHeaderLength, fileTransferHeaderLength+len(fileTransferCommand.Data))
+	msgData[0] = byte(fileTransferCommand.Type)
+	binary.LittleEndian.PutUint32(msgData[1:5], fileTransferCommand.TotalSize)
+	binary.LittleEndian.PutUint32(msgData[5:9], fileTransferCommand.Offset)
+	binary.LittleEndian.PutUint16(msgData[9:11], uint16(len(fileTransferCommand.Data)))
+	return append(msgData, fileTransferCommand.Data...)
+}
+
+func ParseFileTransferStatus(msgData []byte) (*FileTransferStatusReply, error) {
+	if len(msgData) < fileTransferStatusLength {
+		return nil, ReplyDataLengthError
+	}
+	return &FileTransferStatusReply{
+		Action:           msgData[0],
+		Delay:            time.Duration(binary.LittleEndian.Uint16(msgData[1:3])) * time.Millisecond,
+		Status:           FileTransferStatus(int16(binary.LittleEndian.Uint16(msgData[3:5]))),
+		UpdateMessageMax: binary.LittleEndian.Uint16(msgData[5:7]),
+	}, nil
+}
+
+// Interleave reports whether the PD accepts other commands between fragments
+func (fileTransferStatusReply *FileTransferStatusReply) Interleave() bool {
+	return fileTransferStatusReply.Action&FileTransferActionInterleave == FileTransferActionInterleave
+}
+
+func NewFileTransfer(peripheralAddress byte, fileType FileTransferType, data []byte, progress FileTransferProgress) *FileTransfer {
+	return &FileTransfer{peripheralAddress: peripheralAddress, fileType: fileType, data: data, progress: progress}
+}
+
+// Resume restarts an interrupted transfer from offset, typically a previously saved Offset
+func (fileTransfer *FileTransfer) Resume(offset int) error {
+	if offset < 0 || offset > len(fileTransfer.data) {
+		return FileTransferRangeError
+	}
+	fileTransfer.offset = offset
+	fileTransfer.finishing = false
+	fileTransfer.done = false
+	return nil
+}
+
+// Offset is the amount of the file the PD has acknowledged
+func (fileTransfer *FileTransfer) Offset() int {
+	return fileTransfer.offset
+}
+
+func (fileTransfer *FileTransfer) Done() bool {
+	return fileTransfer.done
+}
+
+// LastStatus is the most recent osdp_FTSTAT, nil before the first fragment is sent
+func (fileTransfer *FileTransfer) LastStatus() *FileTransferStatusReply {
+	return fileTransfer.lastStatus
+}
+
+// Step sends the next fragment unless the PD asked for a delay that has not yet passed, and reports whether the
+// transfer is complete. A failed Step leaves the offset unchanged so the next Step resends the same fragment
+func (fileTransfer *FileTransfer) Step(ctx context.Context, osdpMessenger *OSDPMessenger) (bool, error) {
+	if fileTransfer.done {
+		return true, nil
+	}
+	now := time.Now()
+	if now.Before(fileTransfer.nextStep) {
+		return false, nil
+	}
+	if fileTransfer.fragmentLength == 0 {
+		fileTransfer.fragmentLength = osdpMessenger.maxFileTransferFragmentLength(fileTransfer.peripheralAddress)
+	}
+	fragment := []byte{}
+	if !fileTransfer.finishing {
+		end := fileTransfer.offset + fileTransfer.fragmentLength
+		if end > len(fileTransfer.data) {
+			end = len(fileTransfer.data)
+		}
+		fragment = fileTransfer.data[fileTransfer.offset:end]
+	}
+	reply, err := osdpMessenger.SendCommand(ctx, fileTransfer.peripheralAddress, &FileTransferCommand{
+		Type:      fileTransfer.fileType,
+		TotalSize: uint32(len(fileTransfer.data)),
+		Offset:    uint32(fileTransfer.offset),
+		Data:      fragment,
+	})
+	if err != nil {
+		return false, err
+	}
+	if err := expectReply(reply, REPLY_FTSTAT); err != nil {
+		return false, err
+	}
+	fileTransferStatusReply, err := ParseFileTransferStatus(reply.MessageData)
+	if err != nil {
+		return false, err
+	}
+	fileTransfer.lastStatus = fileTransferStatusReply
+	if fileTransferStatusReply.Status < FileTransferProceed {
+		return false, FileTransferRejectedError
+	}
+	fileTransfer.offset += len(fragment)
+	fileTransfer.nextStep = now.Add(fileTransferStatusReply.Delay)
+	if fileTransferStatusReply.UpdateMessageMax > 0 {
+		fileTransfer.fragmentLength = int(fileTransferStatusReply.UpdateMessageMax)
+	}
+	if len(fragment) > 0 && fileTransfer.progress != nil {
+		fileTransfer.progress(fileTransfer.offset, len(fileTransfer.data))
+	}
+	switch fileTransferStatusReply.Status {
+	case FileTransferFinished:
+		fileTransfer.done = true
+	case FileTransferRebooting:
+		// The PD comes back with new firmware, restart its sequence and treat it as newly online
+		osdpMessenger.setOffline(fileTransfer.peripheralAddress)
+		osdpMessenger.peripheral(fileTransfer.peripheralAddress).capabilities = nil
+		fileTransfer.done = true
+	case FileTransferFinishing:
+		fileTransfer.finishing = true
+	case FileTransferProceed:
+		fileTransfer.finishing = fileTransfer.offset == len(fileTransfer.data)
+	}
+	return fileTransfer.done, nil
+}
+
+// Run steps the transfer to completion, waiting out any delay the PD requests. It occupies the bus
+// for the whole transfer, use Step from the poll loop when other PDs need servicing
+func (fileTransfer *FileTransfer) Run(ctx context.Context, osdpMessenger *OSDPMessenger) error {
+	for {
+		done, err := fileTransfer.Step(ctx, osdpMessenger)
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Until(fileTransfer.nextStep)):
+		}
+	}
+}
+
+func (osdpMessenger *OSDPMessenger) maxFileTransferFragmentLength(peripheralAddress byte) int {
+	peripheralCapabilities := osdpMessenger.Capabilities(peripheralAddress)
+	if peripheralCapabilities == nil || peripheralCapabilities.ReceiveBufferSize() <= multiPartPacketOverhead+fileTransferHeaderLength {
+		return defaultMaxFragmentLength
+	}
+	return peripheralCapabilities.ReceiveBufferSize() - multiPartPacketOverhead - fileTransferHeaderLength
+}
@@ -0,0 +1,38 @@
+package osdp
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+)
+
+// KeepActiveCommand keeps the PD's secure channel and reader state alive for Duration without a card read,
+// for transactions such as a PIN prompt that follow a card read
+type KeepActiveCommand struct {
+	Duration time.Duration
+}
+
+func (keepActiveCommand *KeepActiveCommand) Code() OSDPCode {
+	return CMD_KEEPACTIVE
+}
+
+func (keepActiveCommand *KeepActiveCommand) Validate() error {
+	if keepActiveCommand.Duration < 0 || keepActiveCommand.Duration/time.Millisecond > 0xFFFF {
+		return KeepActiveOutOfRangeError
+	}
+	return nil
+}
+
+func (keepActiveCommand *KeepActiveCommand) ToBytes() []byte {
+	msgData := make([]byte, 2)
+	binary.LittleEndian.PutUint16(msgData, uint16(keepActiveCommand.Duration/time.Millisecond))
+	return msgData
+}
+
+func (osdpMessenger *OSDPMessenger) KeepActive(ctx context.Context, peripheralAddress byte, duration time.Duration) error {
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, &KeepActiveCommand{Duration: duration})
+	if err != nil {
+		return err
+	}
+	return expectReply(reply, REPLY_ACK)
+}
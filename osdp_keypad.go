@@ -0,0 +1,175 @@
+package osdp
+
+import "time"
+
+const (
+	KeypadStar byte = '*'
+	KeypadHash byte = '#'
+)
+
+const (
+	keypadDataHeaderLength int  = 2
+	keypadStarCode         byte = 0x7F
+	keypadHashCode         byte = 0x0D
+)
+
+// KeypadData is the osdp_KEYPAD reply with * and # mapped to their ASCII characters
+type KeypadData struct {
+	ReaderNumber byte
+	Keys         string
+}
+
+func ParseKeypadData(msgData []byte) (*KeypadData, error) {
+	if len(msgData) < keypadDataHeaderLength || len(msgData)-keypadDataHeaderLength < int(msgData[1]) {
+		return nil, ReplyDataLengthError
+	}
+	keys := make([]byte, msgData[1])
+	for i := range keys {
+		key := msgData[keypadDataHeaderLength+i]
+		switch key {
+		case keypadStarCode:
+			key = KeypadStar
+		case keypadHashCode:
+			key = KeypadHash
+		}
+		keys[i] = key
+	}
+	return &KeypadData{ReaderNumber: msgData[0], Keys: string(keys)}, nil
+}
+
+// PINSessionConfig controls PIN entry. An InterDigitTimeout of 0 never discards a partial PIN, and a TerminatorKey
+// of 0 completes entry only at MaxLength. * and # are never taken as PIN digits.
+// A CardPINWindow of 0 emits PINs on their own, otherwise a PIN is only emitted paired with a card read within the window
+type PINSessionConfig struct {
+	InterDigitTimeout time.Duration
+	MaxLength         int
+	TerminatorKey     byte
+	CardPINWindow     time.Duration
+}
+
+type PINCredential struct {
+	ReaderNumber byte
+	PIN          string
+	Card         *CardRead
+}
+
+type PINCredentialHandler func(pinCredential *PINCredential)
+
+type pinSession struct {
+	keys       []byte
+	lastKey    time.Time
+	pin        string
+	pinTime    time.Time
+	card       *CardRead
+	cardTime   time.Time
+	pinPending bool
+}
+
+// PINSessions accumulates keypad input per reader and emits completed credentials to the handler
+type PINSessions struct {
+	config   PINSessionConfig
+	handler  PINCredentialHandler
+	sessions map[byte]*pinSession
+}
+
+func NewPINSessions(config PINSessionConfig, handler PINCredentialHandler) *PINSessions {
+	return &PINSessions{config: config, handler: handler, sessions: map[byte]*pinSession{}}
+}
+
+func (pinSessions *PINSessions) session(readerNumber byte) *pinSession {
+	session, ok := pinSessions.sessions[readerNumber]
+	if !ok {
+		session = &pinSession{}
+		pinSessions.sessions[readerNumber] = session
+	}
+	return session
+}
+
+func (pinSessions *PINSessions) HandleKeypad(keypadData *KeypadData) {
+	now := time.Now()
+	session := pinSessions.session(keypadData.ReaderNumber)
+	if pinSessions.interDigitExpired(session, now) {
+		session.keys = nil
+	}
+	for i := 0; i < len(keypadData.Keys); i++ {
+		key := keypadData.Keys[i]
+		session.lastKey = now
+		switch {
+		case key == KeypadStar && pinSessions.config.TerminatorKey != KeypadStar:
+			session.keys = nil
+		case key == pinSessions.config.TerminatorKey:
+			if len(session.keys) > 0 {
+				pinSessions.completePIN(keypadData.ReaderNumber, session, now)
+				session = pinSessions.session(keypadData.ReaderNumber)
+			}
+		case key == KeypadStar || key == KeypadHash:
+		default:
+			session.keys = append(session.keys, key)
+			if pinSessions.config.MaxLength > 0 && len(session.keys) >= pinSessions.config.MaxLength {
+				pinSessions.completePIN(keypadData.ReaderNumber, session, now)
+				session = pinSessions.session(keypadData.ReaderNumber)
+			}
+		}
+	}
+}
+
+func (pinSessions *PINSessions) HandleCardRead(cardRead *CardRead) {
+	if pinSessions.config.CardPINWindow == 0 {
+		return
+	}
+	now := time.Now()
+	session := pinSessions.session(cardRead.ReaderNumber)
+	session.card = cardRead
+	session.cardTime = now
+	if session.pinPending && now.Sub(session.pinTime) <= pinSessions.config.CardPINWindow {
+		pinSessions.emit(cardRead.ReaderNumber, session)
+	}
+}
+
+// CheckTimeouts discards partial PINs and unpaired reads that have expired, call it periodically from the poll loop
+func (pinSessions *PINSessions) CheckTimeouts() {
+	now := time.Now()
+	for readerNumber, session := range pinSessions.sessions {
+		if pinSessions.interDigitExpired(session, now) {
+			session.keys = nil
+		}
+		if session.pinPending && now.Sub(session.pinTime) > pinSessions.config.CardPINWindow {
+			session.pinPending = false
+			session.pin = ""
+		}
+		if session.card != nil && now.Sub(session.cardTime) > pinSessions.config.CardPINWindow {
+			session.card = nil
+		}
+		if len(session.keys) == 0 && !session.pinPending && session.card == nil {
+			delete(pinSessions.sessions, readerNumber)
+		}
+	}
+}
+
+func (pinSessions *PINSessions) interDigitExpired(session *pinSession, now time.Time) bool {
+	interDigitTimeout := pinSessions.config.InterDigitTimeout
+	return interDigitTimeout > 0 && len(session.keys) > 0 && now.Sub(session.lastKey) > interDigitTimeout
+}
+
+func (pinSessions *PINSessions) completePIN(readerNumber byte, session *pinSession, now time.Time) {
+	session.pin = string(session.keys)
+	session.pinTime = now
+	session.keys = nil
+	if pinSessions.config.CardPINWindow == 0 {
+		pinSessions.emit(readerNumber, session)
+		return
+	}
+	if session.card != nil && now.Sub(session.cardTime) <= pinSessions.config.CardPINWindow {
+		pinSessions.emit(readerNumber, session)
+		return
+	}
+	session.pinPending = true
+}
+
+func (pinSessions *PINSessions) emit(readerNumber byte, session *pinSession) {
+	pinCredential := &PINCredential{ReaderNumber: readerNumber, PIN: session.pin, Card: session.card}
+	delete(pinSessions.sessions, readerNumber)
+	if pinSessions.handler != nil {
+		pinSessions.handler(pinCredential)
+	}
+}
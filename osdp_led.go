@@ -0,0 +1,184 @@
+package osdp
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+type LEDColor byte
+
+const (
+	LEDColorBlack   LEDColor = 0x00
+	LEDColorRed     LEDColor = 0x01
+	LEDColorGreen   LEDColor = 0x02
+	LEDColorAmber   LEDColor = 0x03
+	LEDColorBlue    LEDColor = 0x04
+	LEDColorMagenta LEDColor = 0x05
+	LEDColorCyan    LEDColor = 0x06
+	LEDColorWhite   LEDColor = 0x07
+)
+
+type LEDTemporaryControlCode byte
+
+const (
+	LEDTemporaryNOP    LEDTemporaryControlCode = 0x00
+	LEDTemporaryCancel LEDTemporaryControlCode = 0x01 // Cancel any temporary operation and display the permanent state
+	LEDTemporarySet    LEDTemporaryControlCode = 0x02 // Set the temporary state and start the timer
+)
+
+type LEDPermanentControlCode byte
+
+const (
+	LEDPermanentNOP LEDPermanentControlCode = 0x00
+	LEDPermanentSet LEDPermanentControlCode = 0x01
+)
+
+const (
+	ledControlRecordLength int = 14
+	// On, off and timer values are expressed in units of 100ms
+	ledTimeUnit    time.Duration = 100 * time.Millisecond
+	maxLEDDuration time.Duration = 0xFF * ledTimeUnit
+	maxLEDTimer    int           = 0xFFFF
+)
+
+type LEDTemporaryState struct {
+	ControlCode LEDTemporaryControlCode
+	OnTime      byte
+	OffTime     byte
+	OnColor     LEDColor
+	OffColor    LEDColor
+	Timer       uint16
+}
+
+type LEDPermanentState struct {
+	ControlCode LEDPermanentControlCode
+	OnTime      byte
+	OffTime     byte
+	OnColor     LEDColor
+	OffColor    LEDColor
+}
+
+type LEDControl struct {
+	ReaderNumber byte
+	LEDNumber    byte
+	Temporary    LEDTemporaryState
+	Permanent    LEDPermanentState
+}
+
+func (ledControl *LEDControl) Validate() error {
+	if ledControl.Temporary.ControlCode > LEDTemporarySet || ledControl.Permanent.ControlCode > LEDPermanentSet {
+		return InvalidLEDControlCodeError
+	}
+	// A temporary state with no on, off or timer value never shows
+	temporary := ledControl.Temporary
+	if temporary.ControlCode == LEDTemporarySet && (temporary.Timer == 0 || temporary.OnTime == 0 && temporary.OffTime == 0) {
+		return LEDTimeOutOfRangeError
+	}
+	colors := []LEDColor{ledControl.Temporary.OnColor, ledControl.Temporary.OffColor, ledControl.Permanent.OnColor, ledControl.Permanent.OffColor}
+	for _, color := range colors {
+		if color > LEDColorWhite {
+			return InvalidLEDColorError
+		}
+	}
+	return nil
+}
+
+func (ledControl *LEDControl) ToBytes() []byte {
+	timer := make([]byte, 2)
+	binary.LittleEndian.PutUint16(timer, ledControl.Temporary.Timer)
+	return []byte{
+		ledControl.ReaderNumber, ledControl.LEDNumber,
+		byte(ledControl.Temporary.ControlCode), ledControl.Temporary.OnTime, ledControl.Temporary.OffTime,
+		byte(ledControl.Temporary.OnColor), byte(ledControl.Temporary.OffColor), timer[0], timer[1],
+		byte(ledControl.Permanent.ControlCode), ledControl.Permanent.OnTime, ledControl.Permanent.OffTime,
+		byte(ledControl.Permanent.OnColor), byte(ledControl.Permanent.OffColor),
+	}
+}
+
+// LEDCommand holds one or more LED control records sent in a single osdp_LED
+type LEDCommand []LEDControl
+
+func (ledCommand LEDCommand) Code() OSDPCode {
+	return CMD_LED
+}
+
+func (ledCommand LEDCommand) Validate() error {
+	if len(ledCommand) == 0 {
+		return EmptyCommandError
+	}
+	for i := range ledCommand {
+		if err := ledCommand[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ledCommand LEDCommand) ToBytes() []byte {
+	msgData := make([]byte, 0, len(ledCommand)*ledControlRecordLength)
+	for i := range ledCommand {
+		msgData = append(msgData, ledCommand[i].ToBytes()...)
+	}
+	return msgData
+}
+
+func NewLEDMessage(peripheralAddress byte, sequenceNumber byte, ledControls ...LEDControl) (*OSDPMessage, error) {
+	return NewCommandMessage(peripheralAddress, sequenceNumber, LEDCommand(ledControls))
+}
+
+func LEDSteady(readerNumber byte, ledNumber byte, color LEDColor) LEDControl {
+	return LEDControl{
+		ReaderNumber: readerNumber,
+		LEDNumber:    ledNumber,
+		Temporary:    LEDTemporaryState{ControlCode: LEDTemporaryCancel},
+		Permanent:    LEDPermanentState{ControlCode: LEDPermanentSet, OnTime: 1, OffTime: 0, OnColor: color, OffColor: color},
+	}
+}
+
+// LEDFlash flashes the LED flashes times. Times are rounded down to the 100ms the PD counts in, so a non-zero
+// time shorter than that is rejected rather than sent as 0
+func LEDFlash(readerNumber byte, ledNumber byte, color LEDColor, onTime time.Duration, offTime time.Duration, flashes int) (LEDControl, error) {
+	if onTime < ledTimeUnit || onTime > maxLEDDuration || offTime < 0 || offTime > maxLEDDuration || (offTime > 0 && offTime < ledTimeUnit) {
+		return LEDControl{}, LEDTimeOutOfRangeError
+	}
+	onUnits := int(onTime / ledTimeUnit)
+	offUnits := int(offTime / ledTimeUnit)
+	if flashes <= 0 || flashes*(onUnits+offUnits) > maxLEDTimer {
+		return LEDControl{}, LEDTimeOutOfRangeError
+	}
+	return ledFlash(readerNumber, ledNumber, color, byte(onUnits), byte(offUnits), flashes), nil
+}
+
+func ledFlash(readerNumber byte, ledNumber byte, color LEDColor, onTime byte, offTime byte, flashes int) LEDControl {
+	return LEDControl{
+		ReaderNumber: readerNumber,
+		LEDNumber:    ledNumber,
+		Temporary: LEDTemporaryState{
+			ControlCode: LEDTemporarySet, OnTime: onTime, OffTime: offTime,
+			OnColor: color, OffColor: LEDColorBlack, Timer: uint16(flashes * (int(onTime) + int(offTime))),
+		},
+		Permanent: LEDPermanentState{ControlCode: LEDPermanentNOP},
+	}
+}
+
+// LEDFlashThenSteady flashes the LED at 500ms on and off and then leaves it on the given permanent colour once the timer expires
+func LEDFlashThenSteady(readerNumber byte, ledNumber byte, flashColor LEDColor, flashes int, steadyColor LEDColor) (LEDControl, error) {
+	ledControl, err := LEDFlash(readerNumber, ledNumber, flashColor, 500*time.Millisecond, 500*time.Millisecond, flashes)
+	if err != nil {
+		return LEDControl{}, err
+	}
+	ledControl.Permanent = LEDSteady(readerNumber, ledNumber, steadyColor).Permanent
+	return ledControl, nil
+}
+
+func LEDAccessGranted(readerNumber byte, ledNumber byte) LEDControl {
+	ledControl := ledFlash(readerNumber, ledNumber, LEDColorGreen, 5, 5, 2)
+	ledControl.Permanent = LEDSteady(readerNumber, ledNumber, LEDColorRed).Permanent
+	return ledControl
+}
+
+func LEDAccessDenied(readerNumber byte, ledNumber byte) LEDControl {
+	ledControl := ledFlash(readerNumber, ledNumber, LEDColorRed, 5, 5, 3)
+	ledControl.Permanent = LEDSteady(readerNumber, ledNumber, LEDColorRed).Permanent
+	return ledControl
+}
@@ -1,6 +1,8 @@
 package osdp
 
 import (
+	"context"
+	"errors"
 	"io"
 	"time"
 )
@@ -16,13 +18,184 @@ const (
 	OSDPTransmitError  OSDPMessengerEvent = 4
 )
 
+const (
+	osdpDefaultReplyTimeout time.Duration = 200 * time.Millisecond
+	osdpDefaultBusyTimeout  time.Duration = 1 * time.Second
+	osdpBusyPollInterval    time.Duration = 50 * time.Millisecond
+	osdpReplyPollInterval   time.Duration = 50 * time.Millisecond
+	osdpDefaultReceiveSize  uint16        = 1024
+)
+
+type OSDPMessengerMetrics struct {
+	CommandsSent    uint64
+	RepliesReceived uint64
+	ReceiveTimeouts uint64
+	BusyReplies     uint64
+	BusyTimeouts    uint64
+	Retransmissions uint64
+}
+
+type peripheralState struct {
+	sequenceNumber         byte
+	capabilities           *PeripheralCapabilities
+	online                 bool
+	pending                *OSDPMessage
+	announceReceiveSize    bool
+	receiveSizeUnsupported bool
+}
+
 type OSDPMessenger struct {
-	connected   bool
-	transceiver OSDPTransceiver
+	connected    bool
+	transceiver  OSDPTransceiver
+	commandQueue map[byte][]OSDPCommand
+	replyQueue   map[byte][]*OSDPMessage
+	peripherals  map[byte]*peripheralState
+	nakAsError   bool
+	busyTimeout  time.Duration
+	replyTimeout time.Duration
+	receiveSize  uint16
+	retries      uint32
+	retrySpacing time.Duration
+	staleReply   bool
+	metrics      OSDPMessengerMetrics
 }
 
 func NewOSDPMessenger(transceiver OSDPTransceiver, secure bool) *OSDPMessenger {
-	return &OSDPMessenger{connected: false, transceiver: transceiver}
+	return &OSDPMessenger{
+		connected: false, transceiver: transceiver,
+		commandQueue: map[byte][]OSDPCommand{}, replyQueue: map[byte][]*OSDPMessage{}, peripherals: map[byte]*peripheralState{},
+		busyTimeout: osdpDefaultBusyTimeout, replyTimeout: osdpDefaultReplyTimeout, receiveSize: osdpDefaultReceiveSize,
+	}
+}
+
+func (osdpMessenger *OSDPMessenger) peripheral(peripheralAddress byte) *peripheralState {
+	state, ok := osdpMessenger.peripherals[peripheralAddress]
+	if !ok {
+		state = &peripheralState{}
+		osdpMessenger.peripherals[peripheralAddress] = state
+	}
+	return state
+}
+
+// validateCommand also checks the command against the PD capabilities when they are known
+func (osdpMessenger *OSDPMessenger) validateCommand(peripheralAddress byte, osdpCommand OSDPCommand) error {
+	if err := osdpCommand.Validate(); err != nil {
+		return err
+	}
+	capabilityValidator, ok := osdpCommand.(OSDPCapabilityValidator)
+	peripheralCapabilities := osdpMessenger.Capabilities(peripheralAddress)
+	if !ok || peripheralCapabilities == nil {
+		return nil
+	}
+	return capabilityValidator.ValidateCapabilities(peripheralCapabilities)
+}
+
+// nextSequenceNumber starts a PD at 0 and then cycles 1, 2, 3
+func (osdpMessenger *OSDPMessenger) nextSequenceNumber(peripheralAddress byte) byte {
+	state := osdpMessenger.peripheral(peripheralAddress)
+	sequenceNumber := state.sequenceNumber
+	state.sequenceNumber = sequenceNumber%3 + 1
+	return sequenceNumber
+}
+
+// assignSequenceNumber numbers a message about to be sent. A message sent again before the PD answered it
+// is a retransmission and keeps its number. Messages already carrying a MAC were authenticated with their
+// number, so it is kept and the PD's sequence continues from it
+func (osdpMessenger *OSDPMessenger) assignSequenceNumber(osdpMessage *OSDPMessage) {
+	state := osdpMessenger.peripheral(osdpMessage.PeripheralAddress)
+	if osdpMessage == state.pending {
+		return
+	}
+	if osdpMessage.MAC != nil {
+		state.sequenceNumber = osdpMessage.SequenceNumber%3 + 1
+	} else {
+		osdpMessage.SequenceNumber = osdpMessenger.nextSequenceNumber(osdpMessage.PeripheralAddress)
+	}
+	state.pending = osdpMessage
+}
+
+// ResetSequenceNumber makes the next message to the PD use sequence number 0, which the PD takes as a restart
+func (osdpMessenger *OSDPMessenger) ResetSequenceNumber(peripheralAddress byte) {
+	state := osdpMessenger.peripheral(peripheralAddress)
+	state.sequenceNumber = 0
+	state.pending = nil
+}
+
+// SetBusyTimeout bounds how long a PD answering osdp_BUSY is re-polled before giving up with a receive timeout
+func (osdpMessenger *OSDPMessenger) SetBusyTimeout(busyTimeout time.Duration) {
+	osdpMessenger.busyTimeout = busyTimeout
+}
+
+// SetReplyTimeout bounds how long the typed helpers wait for each reply, the context deadline bounds the whole exchange
+func (osdpMessenger *OSDPMessenger) SetReplyTimeout(replyTimeout time.Duration) {
+	osdpMessenger.replyTimeout = replyTimeout
+}
+
+func (osdpMessenger *OSDPMessenger) Metrics() OSDPMessengerMetrics {
+	return osdpMessenger.metrics
+}
+
+// SetNakAsError makes SendAndReceive return osdp_NAK replies as a *NakError instead of a message
+func (osdpMessenger *OSDPMessenger) SetNakAsError(nakAsError bool) {
+	osdpMessenger.nakAsError = nakAsError
+}
+
+// QueueCommand schedules commands to be sent to a PD in place of its next polls, in order
+func (osdpMessenger *OSDPMessenger) QueueCommand(peripheralAddress byte, osdpCommands ...OSDPCommand) error {
+	for _, osdpCommand := range osdpCommands {
+		if err := osdpMessenger.validateCommand(peripheralAddress, osdpCommand); err != nil {
+			return err
+		}
+	}
+	osdpMessenger.commandQueue[peripheralAddress] = append(osdpMessenger.commandQueue[peripheralAddress], osdpCommands...)
+	return nil
+}
+
+func (osdpMessenger *OSDPMessenger) PendingCommands(peripheralAddress byte) int {
+	return len(osdpMessenger.commandQueue[peripheralAddress])
+}
+
+func (osdpMessenger *OSDPMessenger) queueReply(peripheralAddress byte, osdpMessage *OSDPMessage) {
+	osdpMessenger.replyQueue[peripheralAddress] = append(osdpMessenger.replyQueue[peripheralAddress], osdpMessage)
+}
+
+func (osdpMessenger *OSDPMessenger) PendingReplies(peripheralAddress byte) int {
+	return len(osdpMessenger.replyQueue[peripheralAddress])
+}
+
+// NextReply dequeues a reply the PD sent while the messenger was polling it for something else, such as a card
+// read during a biometric scan, or returns nil. The poll loop should handle these as if it had polled for them
+func (osdpMessenger *OSDPMessenger) NextReply(peripheralAddress byte) *OSDPMessage {
+	queue := osdpMessenger.replyQueue[peripheralAddress]
+	if len(queue) == 0 {
+		return nil
+	}
+	if len(queue) == 1 {
+		delete(osdpMessenger.replyQueue, peripheralAddress)
+	} else {
+		osdpMessenger.replyQueue[peripheralAddress] = queue[1:]
+	}
+	return queue[0]
+}
+
+// NextMessage dequeues the next scheduled command for a PD, or builds an osdp_POLL if none is pending.
+// A PD that just came online is first sent osdp_ACURXSIZE.
+// The sequence number is assigned when the message is sent
+func (osdpMessenger *OSDPMessenger) NextMessage(peripheralAddress byte) (*OSDPMessage, error) {
+	if acuReceiveSizeCommand := osdpMessenger.takeReceiveSizeAnnouncement(peripheralAddress); acuReceiveSizeCommand != nil {
+		return NewCommandMessage(peripheralAddress, 0, acuReceiveSizeCommand)
+	}
+	queue := osdpMessenger.commandQueue[peripheralAddress]
+	if len(queue) == 0 {
+		return NewOSDPMessage(CMD_POLL, peripheralAddress, 0, []byte{})
+	}
+	osdpCommand := queue[0]
+	if len(queue) == 1 {
+		delete(osdpMessenger.commandQueue, peripheralAddress)
+	} else {
+		osdpMessenger.commandQueue[peripheralAddress] = queue[1:]
+	}
+	return NewCommandMessage(peripheralAddress, 0, osdpCommand)
 }
 
 func (osdpMessenger *OSDPMessenger) SendOSDPCommand(osdpMessage *OSDPMessage, timeout time.Duration) error {
@@ -32,16 +205,30 @@ func (osdpMessenger *OSDPMessenger) SendOSDPCommand(osdpMessage *OSDPMessage, ti
 		return err
 	}
 
-	return osdpMessenger.transceiver.Transmit(osdpPacket.ToBytes())
+	err = osdpMessenger.transceiver.Transmit(osdpPacket.ToBytes())
+	if err != nil {
+		return err
+	}
+	osdpMessenger.metrics.CommandsSent++
+	return nil
 }
 
 func (osdpMessenger *OSDPMessenger) ReceiveResponse(timeout time.Duration) (*OSDPMessage, error) {
+	return osdpMessenger.receiveResponse(context.Background(), timeout)
+}
+
+// receiveResponse stops waiting for the reply as soon as the context is done
+func (osdpMessenger *OSDPMessenger) receiveResponse(ctx context.Context, timeout time.Duration) (*OSDPMessage, error) {
 	payload := []byte{}
 	timeStart := time.Now()
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		responseData, err := osdpMessenger.transceiver.Receive()
 		if err != nil {
 			if time.Since(timeStart) > timeout {
+				osdpMessenger.metrics.ReceiveTimeouts++
 				return nil, OSDPReceiveTimeoutError
 			}
 		}
@@ -49,6 +236,7 @@ func (osdpMessenger *OSDPMessenger) ReceiveResponse(timeout time.Duration) (*OSD
 		payload = append(payload, responseData...)
 		osdpPacket, err := NewPacketFromBytes(payload)
 		if err == nil {
+			osdpMessenger.metrics.RepliesReceived++
 			sequenceNumber := osdpPacket.msgCtrlInfo & 0x03
 			return &OSDPMessage{
 				MessageCode:       OSDPCode(osdpPacket.msgCode),
@@ -66,15 +254,171 @@ func (osdpMessenger *OSDPMessenger) ReceiveResponse(timeout time.Duration) (*OSD
 			return nil, err
 		}
 		if time.Since(timeStart) > timeout {
+			osdpMessenger.metrics.ReceiveTimeouts++
 			return nil, OSDPReceiveTimeoutError
 		}
 	}
 }
 
+// SendAndReceive sends a message and waits for the PD's reply. The messenger numbers every message it sends
+// and rejects replies that do not echo the number. Retransmissions keep the number, but a PD that stopped
+// answering is marked offline and the next message to it restarts the sequence at 0
 func (osdpMessenger *OSDPMessenger) SendAndReceive(osdpMessage *OSDPMessage, writeTimeout time.Duration, readTimeout time.Duration) (*OSDPMessage, error) {
-	err := osdpMessenger.SendOSDPCommand(osdpMessage, writeTimeout)
+	return osdpMessenger.sendAndReceive(context.Background(), osdpMessage, writeTimeout, readTimeout)
+}
+
+func (osdpMessenger *OSDPMessenger) sendAndReceive(ctx context.Context, osdpMessage *OSDPMessage, writeTimeout time.Duration, readTimeout time.Duration) (*OSDPMessage, error) {
+	// Drop whatever arrived late for a message that went unanswered before sending the next one
+	if osdpMessenger.staleReply {
+		osdpMessenger.transceiver.Reset()
+	}
+	osdpMessenger.assignSequenceNumber(osdpMessage)
+	reply, err := osdpMessenger.exchangeWithRetries(ctx, osdpMessage, writeTimeout, readTimeout)
+	if err != nil {
+		return nil, err
+	}
+	osdpMessenger.peripheral(osdpMessage.PeripheralAddress).pending = nil
+	osdpMessenger.setOnline(osdpMessage.PeripheralAddress)
+	osdpMessenger.checkReceiveSizeReply(osdpMessage, reply)
+	if reply.MessageCode == REPLY_BUSY {
+		reply, err = osdpMessenger.pollWhileBusy(ctx, osdpMessage, writeTimeout, readTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// The PD lost track of the sequence, start it again from 0
+	if errors.Is(reply.NakError(), NakBadSequenceError) {
+		osdpMessenger.ResetSequenceNumber(osdpMessage.PeripheralAddress)
+	}
+	if osdpMessenger.nakAsError {
+		if err := reply.NakError(); err != nil {
+			return nil, err
+		}
+	}
+	return reply, nil
+}
+
+// exchange sends the message once and receives the reply to it
+func (osdpMessenger *OSDPMessenger) exchange(ctx context.Context, osdpMessage *OSDPMessage, writeTimeout time.Duration, readTimeout time.Duration) (*OSDPMessage, error) {
+	if err := osdpMessenger.SendOSDPCommand(osdpMessage, writeTimeout); err != nil {
+		return nil, err
+	}
+	return osdpMessenger.receiveReply(ctx, osdpMessage.SequenceNumber, readTimeout)
+}
+
+// receiveReply receives the reply numbered sequenceNumber. After a message went unanswered its reply may still
+// turn up late, so until the next matching reply, replies with another number are discarded rather than failing
+func (osdpMessenger *OSDPMessenger) receiveReply(ctx context.Context, sequenceNumber byte, readTimeout time.Duration) (*OSDPMessage, error) {
+	timeStart := time.Now()
+	for {
+		reply, err := osdpMessenger.receiveResponse(ctx, readTimeout-time.Since(timeStart))
+		if err != nil {
+			osdpMessenger.staleReply = true
+			return nil, err
+		}
+		if reply.SequenceNumber == sequenceNumber {
+			osdpMessenger.staleReply = false
+			return reply, nil
+		}
+		if !osdpMessenger.staleReply {
+			return nil, SequenceMismatchError
+		}
+	}
+}
+
+// pollWhileBusy re-polls a PD that answered osdp_BUSY with the same sequence number until it gives its real reply
+func (osdpMessenger *OSDPMessenger) pollWhileBusy(ctx context.Context, osdpMessage *OSDPMessage, writeTimeout time.Duration, readTimeout time.Duration) (*OSDPMessage, error) {
+	busyStart := time.Now()
+	pollMessage, err := NewOSDPMessage(CMD_POLL, osdpMessage.PeripheralAddress, osdpMessage.SequenceNumber, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	for {
+		osdpMessenger.metrics.BusyReplies++
+		if time.Since(busyStart)+osdpBusyPollInterval > osdpMessenger.busyTimeout {
+			osdpMessenger.metrics.BusyTimeouts++
+			return nil, OSDPReceiveTimeoutError
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(osdpBusyPollInterval):
+		}
+		if err := osdpMessenger.SendOSDPCommand(pollMessage, writeTimeout); err != nil {
+			return nil, err
+		}
+		reply, err := osdpMessenger.receiveReply(ctx, pollMessage.SequenceNumber, readTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if reply.MessageCode != REPLY_BUSY {
+			return reply, nil
+		}
+	}
+}
+
+func (osdpMessenger *OSDPMessenger) sendAndReceiveContext(ctx context.Context, osdpCode OSDPCode, peripheralAddress byte, msgData []byte) (*OSDPMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := osdpMessenger.announceReceiveSize(ctx, peripheralAddress); err != nil {
+		return nil, err
+	}
+	osdpMessage, err := NewOSDPMessage(osdpCode, peripheralAddress, 0, msgData)
 	if err != nil {
 		return nil, err
 	}
-	return osdpMessenger.ReceiveResponse(readTimeout)
+	timeout := osdpMessenger.attemptTimeout(ctx)
+	return osdpMessenger.sendAndReceive(ctx, osdpMessage, timeout, timeout)
+}
+
+// SendCommand sends a typed command to a PD and waits for its reply, bounded by the context deadline
+func (osdpMessenger *OSDPMessenger) SendCommand(ctx context.Context, peripheralAddress byte, osdpCommand OSDPCommand) (*OSDPMessage, error) {
+	if err := osdpMessenger.validateCommand(peripheralAddress, osdpCommand); err != nil {
+		return nil, err
+	}
+	return osdpMessenger.sendAndReceiveContext(ctx, osdpCommand.Code(), peripheralAddress, osdpCommand.ToBytes())
+}
+
+// pollForReply polls a PD until it sends the given reply, for operations the PD acknowledges first and completes later.
+// Anything else the PD reports meanwhile, such as card reads or keypad data from its other readers, is queued
+func (osdpMessenger *OSDPMessenger) pollForReply(ctx context.Context, peripheralAddress byte, replyCode OSDPCode) (*OSDPMessage, error) {
+	for {
+		reply, err := osdpMessenger.sendAndReceiveContext(ctx, CMD_POLL, peripheralAddress, []byte{})
+		if err != nil {
+			return nil, err
+		}
+		if reply.MessageCode == replyCode {
+			return reply, nil
+		}
+		if err := reply.NakError(); err != nil {
+			return nil, err
+		}
+		if reply.MessageCode != REPLY_ACK {
+			osdpMessenger.queueReply(peripheralAddress, reply)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(osdpReplyPollInterval):
+		}
+	}
+}
+
+// withDefaultTimeout bounds operations that poll for their result when the caller did not set a deadline
+func withDefaultTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func expectReply(osdpMessage *OSDPMessage, osdpCode OSDPCode) error {
+	if err := osdpMessage.NakError(); err != nil && osdpCode != REPLY_NAK {
+		return err
+	}
+	if osdpMessage.MessageCode != osdpCode {
+		return UnexpectedReplyError
+	}
+	return nil
 }
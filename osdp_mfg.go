@@ -0,0 +1,179 @@
+package osdp
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	manufacturerHeaderLength int = 4
+	vendorCodeLength         int = 3
+)
+
+// ManufacturerCommand is an osdp_MFG command, the vendor code and command ID are followed by vendor defined data
+type ManufacturerCommand struct {
+	VendorCode VendorCode
+	CommandID  byte
+	Payload    []byte
+}
+
+// ManufacturerReply is an osdp_MFGREP reply with the same layout as ManufacturerCommand
+type ManufacturerReply struct {
+	VendorCode VendorCode
+	CommandID  byte
+	Payload    []byte
+}
+
+// ManufacturerStatus is an osdp_MFGSTATR, a vendor defined status report in place of an osdp_MFGREP
+type ManufacturerStatus struct {
+	VendorCode VendorCode
+	Data       []byte
+}
+
+// ManufacturerError is an osdp_MFGERRR, the PD's vendor defined report that an osdp_MFG failed
+type ManufacturerError struct {
+	VendorCode VendorCode
+	Data       []byte
+}
+
+// ManufacturerCodec converts a vendor extension between Go values and the payload after the OSDP vendor header
+type ManufacturerCodec interface {
+	EncodeCommand(value interface{}) ([]byte, error)
+	DecodeReply(payload []byte) (interface{}, error)
+}
+
+type manufacturerKey struct {
+	vendorCode VendorCode
+	commandID  byte
+}
+
+type ManufacturerRegistry struct {
+	codecs map[manufacturerKey]ManufacturerCodec
+}
+
+func (manufacturerCommand *ManufacturerCommand) Code() OSDPCode {
+	return CMD_MFG
+}
+
+func (manufacturerCommand *ManufacturerCommand) Validate() error {
+	return nil
+}
+
+func (manufacturerCommand *ManufacturerCommand) ToBytes() []byte {
+	msgData := make([]byte, 0, manufacturerHeaderLength+len(manufacturerCommand.Payload))
+	msgData = append(msgData, manufacturerCommand.VendorCode[:]...)
+	msgData = append(msgData, manufacturerCommand.CommandID)
+	return append(msgData, manufacturerCommand.Payload...)
+}
+
+func ParseManufacturerReply(msgData []byte) (*ManufacturerReply, error) {
+	if len(msgData) < manufacturerHeaderLength {
+		return nil, ReplyDataLengthError
+	}
+	manufacturerReply := &ManufacturerReply{CommandID: msgData[3], Payload: msgData[manufacturerHeaderLength:]}
+	copy(manufacturerReply.VendorCode[:], msgData[0:3])
+	return manufacturerReply, nil
+}
+
+func ParseManufacturerStatus(msgData []byte) (*ManufacturerStatus, error) {
+	if len(msgData) < vendorCodeLength {
+		return nil, ReplyDataLengthError
+	}
+	manufacturerStatus := &ManufacturerStatus{Data: msgData[vendorCodeLength:]}
+	copy(manufacturerStatus.VendorCode[:], msgData[0:vendorCodeLength])
+	return manufacturerStatus, nil
+}
+
+func ParseManufacturerError(msgData []byte) (*ManufacturerError, error) {
+	if len(msgData) < vendorCodeLength {
+		return nil, ReplyDataLengthError
+	}
+	manufacturerError := &ManufacturerError{Data: msgData[vendorCodeLength:]}
+	copy(manufacturerError.VendorCode[:], msgData[0:vendorCodeLength])
+	return manufacturerError, nil
+}
+
+func (manufacturerError *ManufacturerError) Error() string {
+	return fmt.Sprintf("OSDP Manufacturer Error from %v: % X", manufacturerError.VendorCode, manufacturerError.Data)
+}
+
+func NewManufacturerRegistry() *ManufacturerRegistry {
+	return &ManufacturerRegistry{codecs: map[manufacturerKey]ManufacturerCodec{}}
+}
+
+func (manufacturerRegistry *ManufacturerRegistry) Register(vendorCode VendorCode, commandID byte, manufacturerCodec ManufacturerCodec) error {
+	key := manufacturerKey{vendorCode: vendorCode, commandID: commandID}
+	if _, ok := manufacturerRegistry.codecs[key]; ok {
+		return MfgCommandRegisteredError
+	}
+	manufacturerRegistry.codecs[key] = manufacturerCodec
+	return nil
+}
+
+func (manufacturerRegistry *ManufacturerRegistry) codec(vendorCode VendorCode, commandID byte) (ManufacturerCodec, error) {
+	manufacturerCodec, ok := manufacturerRegistry.codecs[manufacturerKey{vendorCode: vendorCode, commandID: commandID}]
+	if !ok {
+		return nil, UnknownMfgCommandError
+	}
+	return manufacturerCodec, nil
+}
+
+func (manufacturerRegistry *ManufacturerRegistry) NewCommand(vendorCode VendorCode, commandID byte, value interface{}) (*ManufacturerCommand, error) {
+	manufacturerCodec, err := manufacturerRegistry.codec(vendorCode, commandID)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := manufacturerCodec.EncodeCommand(value)
+	if err != nil {
+		return nil, err
+	}
+	return &ManufacturerCommand{VendorCode: vendorCode, CommandID: commandID, Payload: payload}, nil
+}
+
+// DecodeReply decodes an osdp_MFGREP message with the codec registered for its vendor code and command ID.
+// An osdp_MFGSTATR is returned as a *ManufacturerStatus and an osdp_MFGERRR as a *ManufacturerError
+func (manufacturerRegistry *ManufacturerRegistry) DecodeReply(osdpMessage *OSDPMessage) (interface{}, error) {
+	switch osdpMessage.MessageCode {
+	case REPLY_MFGSTATR:
+		manufacturerStatus, err := ParseManufacturerStatus(osdpMessage.MessageData)
+		if err != nil {
+			return nil, err
+		}
+		return manufacturerStatus, nil
+	case REPLY_MFGERRR:
+		manufacturerError, err := ParseManufacturerError(osdpMessage.MessageData)
+		if err != nil {
+			return nil, err
+		}
+		return nil, manufacturerError
+	}
+	if err := expectReply(osdpMessage, REPLY_MFGREP); err != nil {
+		return nil, err
+	}
+	manufacturerReply, err := ParseManufacturerReply(osdpMessage.MessageData)
+	if err != nil {
+		return nil, err
+	}
+	manufacturerCodec, err := manufacturerRegistry.codec(manufacturerReply.VendorCode, manufacturerReply.CommandID)
+	if err != nil {
+		return nil, err
+	}
+	return manufacturerCodec.DecodeReply(manufacturerReply.Payload)
+}
+
+// SendManufacturerCommand encodes value with the registered codec, sends it and decodes the reply as DecodeReply does.
+// PDs that only acknowledge the command return a nil value
+func (osdpMessenger *OSDPMessenger) SendManufacturerCommand(ctx context.Context, peripheralAddress byte, manufacturerRegistry *ManufacturerRegistry, vendorCode VendorCode, commandID byte, value interface{}) (interface{}, error) {
+	manufacturerCommand, err := manufacturerRegistry.NewCommand(vendorCode, commandID, value)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, manufacturerCommand)
+	if err != nil {
+		return nil, err
+	}
+	if reply.MessageCode == REPLY_ACK {
+		return nil, nil
+	}
+	return manufacturerRegistry.DecodeReply(reply)
+}
@@ -5,3 +5,10 @@ type OSDPTransceiver interface {
 	Receive() ([]byte, error) // Byte slice received must only have bytes received returned, no extra padding
 	Reset() error             // Reset the transceiver in case of error
 }
+
+// OSDPBaudRateTransceiver is optionally implemented by transceivers that can change the line speed, used by osdp_COMSET
+type OSDPBaudRateTransceiver interface {
+	OSDPTransceiver
+	BaudRate() uint32
+	SetBaudRate(baudRate uint32) error
+}
@@ -0,0 +1,175 @@
+package osdp
+
+import (
+	"context"
+	"encoding/binary"
+)
+
+const (
+	multiPartHeaderLength int = 6
+	// Packet header, message code, MAC, checksum and a secure block around the fragment
+	multiPartPacketOverhead  int = 20
+	defaultMaxFragmentLength int = 128
+)
+
+// MultiPartFragment is one piece of a message too large for the PD receive buffer,
+// sent as the whole message length, this fragment's offset and length, then the fragment itself
+type MultiPartFragment struct {
+	WholeLength uint16
+	Offset      uint16
+	Data        []byte
+}
+
+func (multiPartFragment *MultiPartFragment) ToBytes() []byte {
+	msgData := make([]byte, multiPartHeaderLength, multiPartHeaderLength+len(multiPartFragment.Data))
+	binary.LittleEndian.PutUint16(msgData[0:2], multiPartFragment.WholeLength)
+	binary.LittleEndian.PutUint16(msgData[2:4], multiPartFragment.Offset)
+	binary.LittleEndian.PutUint16(msgData[4:6], uint16(len(multiPartFragment.Data)))
+	return append(msgData, multiPartFragment.Data...)
+}
+
+func ParseMultiPartFragment(msgData []byte) (*MultiPartFragment, error) {
+	if len(msgData) < multiPartHeaderLength {
+		return nil, ReplyDataLengthError
+	}
+	wholeLength := binary.LittleEndian.Uint16(msgData[0:2])
+	offset := binary.LittleEndian.Uint16(msgData[2:4])
+	fragmentLength := int(binary.LittleEndian.Uint16(msgData[4:6]))
+	if len(msgData)-multiPartHeaderLength < fragmentLength || int(offset)+fragmentLength > int(wholeLength) {
+		return nil, ReplyDataLengthError
+	}
+	return &MultiPartFragment{
+		WholeLength: wholeLength,
+		Offset:      offset,
+		Data:        msgData[multiPartHeaderLength : multiPartHeaderLength+fragmentLength],
+	}, nil
+}
+
+func SplitMultiPart(data []byte, maxFragmentLength int) ([]*MultiPartFragment, error) {
+	if len(data) > 0xFFFF {
+		return nil, MultiPartTooLargeError
+	}
+	if maxFragmentLength <= 0 {
+		maxFragmentLength = defaultMaxFragmentLength
+	}
+	fragments := []*MultiPartFragment{}
+	for offset := 0; ; offset += maxFragmentLength {
+		end := offset + maxFragmentLength
+		if end > len(data) {
+			end = len(data)
+		}
+		fragments = append(fragments, &MultiPartFragment{WholeLength: uint16(len(data)), Offset: uint16(offset), Data: data[offset:end]})
+		if end == len(data) {
+			break
+		}
+	}
+	return fragments, nil
+}
+
+// MultiPartAssembler reassembles fragments, which must arrive in order
+type MultiPartAssembler struct {
+	data        []byte
+	wholeLength int
+}
+
+func (multiPartAssembler *MultiPartAssembler) Add(multiPartFragment *MultiPartFragment) (bool, error) {
+	if len(multiPartAssembler.data) == 0 {
+		multiPartAssembler.wholeLength = int(multiPartFragment.WholeLength)
+	}
+	if int(multiPartFragment.WholeLength) != multiPartAssembler.wholeLength || int(multiPartFragment.Offset) != len(multiPartAssembler.data) {
+		return false, MultiPartSequenceError
+	}
+	multiPartAssembler.data = append(multiPartAssembler.data, multiPartFragment.Data...)
+	return multiPartAssembler.Complete(), nil
+}
+
+func (multiPartAssembler *MultiPartAssembler) Complete() bool {
+	return len(multiPartAssembler.data) == multiPartAssembler.wholeLength
+}
+
+func (multiPartAssembler *MultiPartAssembler) Data() []byte {
+	return multiPartAssembler.data
+}
+
+// multiPartEnabled is true when the PD advertised a largest combined message size in osdp_PDCAP
+func (osdpMessenger *OSDPMessenger) multiPartEnabled(peripheralAddress byte) bool {
+	peripheralCapabilities := osdpMessenger.Capabilities(peripheralAddress)
+	return peripheralCapabilities != nil && peripheralCapabilities.LargestCombinedMessageSize() > 0
+}
+
+// needsMultiPart is true when a message of msgLength bytes does not fit the PD receive buffer and the PD accepts
+// multi-part messages. Messages that fit are sent whole, without a fragment header
+func (osdpMessenger *OSDPMessenger) needsMultiPart(peripheralAddress byte, msgLength int) bool {
+	if !osdpMessenger.multiPartEnabled(peripheralAddress) {
+		return false
+	}
+	receiveBufferSize := osdpMessenger.Capabilities(peripheralAddress).ReceiveBufferSize()
+	return receiveBufferSize > 0 && msgLength+multiPartPacketOverhead > receiveBufferSize
+}
+
+// isMultiPartFragment is true when msgData is laid out as a fragment: a header whose fragment length accounts
+// for exactly the rest of the message and whose offset and length fall within the whole length
+func isMultiPartFragment(msgData []byte) bool {
+	if len(msgData) < multiPartHeaderLength {
+		return false
+	}
+	wholeLength := int(binary.LittleEndian.Uint16(msgData[0:2]))
+	offset := int(binary.LittleEndian.Uint16(msgData[2:4]))
+	fragmentLength := int(binary.LittleEndian.Uint16(msgData[4:6]))
+	return fragmentLength == len(msgData)-multiPartHeaderLength && offset+fragmentLength <= wholeLength
+}
+
+func (osdpMessenger *OSDPMessenger) maxFragmentLength(peripheralAddress byte) int {
+	peripheralCapabilities := osdpMessenger.Capabilities(peripheralAddress)
+	if peripheralCapabilities == nil || peripheralCapabilities.ReceiveBufferSize() <= multiPartPacketOverhead+multiPartHeaderLength {
+		return defaultMaxFragmentLength
+	}
+	return peripheralCapabilities.ReceiveBufferSize() - multiPartPacketOverhead - multiPartHeaderLength
+}
+
+// sendMultiPart sends each fragment of data in its own message and returns the PD reply to the last one
+func (osdpMessenger *OSDPMessenger) sendMultiPart(ctx context.Context, osdpCode OSDPCode, peripheralAddress byte, data []byte) (*OSDPMessage, error) {
+	peripheralCapabilities := osdpMessenger.Capabilities(peripheralAddress)
+	if peripheralCapabilities != nil && len(data) > peripheralCapabilities.LargestCombinedMessageSize() {
+		return nil, MultiPartTooLargeError
+	}
+	fragments, err := SplitMultiPart(data, osdpMessenger.maxFragmentLength(peripheralAddress))
+	if err != nil {
+		return nil, err
+	}
+	var reply *OSDPMessage
+	for i, fragment := range fragments {
+		reply, err = osdpMessenger.sendAndReceiveContext(ctx, osdpCode, peripheralAddress, fragment.ToBytes())
+		if err != nil {
+			return nil, err
+		}
+		if i < len(fragments)-1 {
+			if err := expectReply(reply, REPLY_ACK); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return reply, nil
+}
+
+// receiveMultiPart collects the remaining fragments of a reply by polling the PD
+func (osdpMessenger *OSDPMessenger) receiveMultiPart(ctx context.Context, peripheralAddress byte, reply *OSDPMessage) ([]byte, error) {
+	multiPartAssembler := &MultiPartAssembler{}
+	for {
+		multiPartFragment, err := ParseMultiPartFragment(reply.MessageData)
+		if err != nil {
+			return nil, err
+		}
+		complete, err := multiPartAssembler.Add(multiPartFragment)
+		if err != nil {
+			return nil, err
+		}
+		if complete {
+			return multiPartAssembler.Data(), nil
+		}
+		reply, err = osdpMessenger.pollForReply(ctx, peripheralAddress, reply.MessageCode)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
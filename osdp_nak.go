@@ -0,0 +1,66 @@
+package osdp
+
+import "fmt"
+
+// NakError is the error code and extra data of an osdp_NAK. errors.Is matches it against the Nak sentinels by code
+type NakError struct {
+	ErrorCode byte
+	Data      []byte
+}
+
+var (
+	NakBadCRCError               = &NakError{ErrorCode: ERR_BAD_CRC}
+	NakBadLengthError            = &NakError{ErrorCode: ERR_BAD_LEN}
+	NakBadCommandError           = &NakError{ErrorCode: ERR_BAD_CMD}
+	NakBadSequenceError          = &NakError{ErrorCode: ERR_BAD_SEQ}
+	NakUnsupportedSecurityError  = &NakError{ErrorCode: ERR_UNSUPPORTED_SEC}
+	NakUnmetSecurityError        = &NakError{ErrorCode: ERR_UNMET_SECURITY_CONDITIONS}
+	NakUnsupportedBioTypeError   = &NakError{ErrorCode: ERR_UNSUPPORTED_BIO_TYPE}
+	NakUnsupportedBioFormatError = &NakError{ErrorCode: ERR_UNSUPPORTED_BIO_FORMAT}
+	NakUnknownError              = &NakError{ErrorCode: ERR_UNKNOWN}
+)
+
+var nakErrorDescriptions = map[byte]string{
+	ERR_NONE:                      "No error",
+	ERR_BAD_CRC:                   "Message check character(s) error",
+	ERR_BAD_LEN:                   "Command length error",
+	ERR_BAD_CMD:                   "Unknown command code",
+	ERR_BAD_SEQ:                   "Unexpected sequence number",
+	ERR_UNSUPPORTED_SEC:           "Unsupported security block or security conditions",
+	ERR_UNMET_SECURITY_CONDITIONS: "Encrypted communication required",
+	ERR_UNSUPPORTED_BIO_TYPE:      "Biometric type not supported",
+	ERR_UNSUPPORTED_BIO_FORMAT:    "Biometric format not supported",
+	ERR_UNKNOWN:                   "Unable to process command record",
+}
+
+func ParseNakError(msgData []byte) (*NakError, error) {
+	if len(msgData) < 1 {
+		return nil, ReplyDataLengthError
+	}
+	return &NakError{ErrorCode: msgData[0], Data: msgData[1:]}, nil
+}
+
+func (nakError *NakError) Error() string {
+	description, ok := nakErrorDescriptions[nakError.ErrorCode]
+	if !ok {
+		description = "Unknown error"
+	}
+	return fmt.Sprintf("OSDP NAK 0x%02X: %s", nakError.ErrorCode, description)
+}
+
+func (nakError *NakError) Is(target error) bool {
+	targetNak, ok := target.(*NakError)
+	return ok && targetNak.ErrorCode == nakError.ErrorCode
+}
+
+// NakError returns the parsed error of an osdp_NAK reply, or nil for any other message
+func (osdpMessage *OSDPMessage) NakError() error {
+	if osdpMessage.MessageCode != REPLY_NAK {
+		return nil
+	}
+	nakError, err := ParseNakError(osdpMessage.MessageData)
+	if err != nil {
+		return err
+	}
+	return nakError
+}
@@ -0,0 +1,121 @@
+package osdp
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+)
+
+type OutputControlCode byte
+
+const (
+	OutputNOP                     OutputControlCode = 0x00
+	OutputPermanentOffAbortTimed  OutputControlCode = 0x01
+	OutputPermanentOnAbortTimed   OutputControlCode = 0x02
+	OutputPermanentOffAllowTimed  OutputControlCode = 0x03
+	OutputPermanentOnAllowTimed   OutputControlCode = 0x04
+	OutputTemporaryOnResumeState  OutputControlCode = 0x05
+	OutputTemporaryOffResumeState OutputControlCode = 0x06
+)
+
+const (
+	outputControlRecordLength      int           = 4
+	outputTimerUnit                time.Duration = 100 * time.Millisecond
+	maxOutputTimerDuration         time.Duration = 0xFFFF * outputTimerUnit
+	outputStatusActive             byte          = 0x01
+	outputStatusConfirmationWindow time.Duration = 100 * time.Millisecond
+)
+
+// OutputControl is a single osdp_OUT record. Timer is in units of 100ms and only applies to temporary control codes
+type OutputControl struct {
+	OutputNumber byte
+	ControlCode  OutputControlCode
+	Timer        uint16
+}
+
+func (outputControl *OutputControl) ToBytes() []byte {
+	timer := make([]byte, 2)
+	binary.LittleEndian.PutUint16(timer, outputControl.Timer)
+	return []byte{outputControl.OutputNumber, byte(outputControl.ControlCode), timer[0], timer[1]}
+}
+
+type OutputCommand []OutputControl
+
+func (outputCommand OutputCommand) Code() OSDPCode {
+	return CMD_OUT
+}
+
+func (outputCommand OutputCommand) Validate() error {
+	if len(outputCommand) == 0 {
+		return EmptyCommandError
+	}
+	for _, outputControl := range outputCommand {
+		if outputControl.ControlCode > OutputTemporaryOffResumeState {
+			return InvalidOutputControlError
+		}
+	}
+	return nil
+}
+
+func (outputCommand OutputCommand) ToBytes() []byte {
+	msgData := make([]byte, 0, len(outputCommand)*outputControlRecordLength)
+	for i := range outputCommand {
+		msgData = append(msgData, outputCommand[i].ToBytes()...)
+	}
+	return msgData
+}
+
+func NewOutputPulse(outputNumber byte, duration time.Duration) (*OutputControl, error) {
+	if duration < outputTimerUnit || duration > maxOutputTimerDuration {
+		return nil, OutputTimerOutOfRangeError
+	}
+	return &OutputControl{OutputNumber: outputNumber, ControlCode: OutputTemporaryOnResumeState, Timer: uint16(duration / outputTimerUnit)}, nil
+}
+
+// ParseOutputStatus decodes osdp_OSTATR, one entry per output with true meaning active
+func ParseOutputStatus(msgData []byte) []bool {
+	outputStatus := make([]bool, len(msgData))
+	for i := range msgData {
+		outputStatus[i] = msgData[i] == outputStatusActive
+	}
+	return outputStatus
+}
+
+// PulseOutput turns an output on for the given duration and confirms that it became active, from the osdp_OSTATR
+// the PD answers with or else with an osdp_OSTAT
+func (osdpMessenger *OSDPMessenger) PulseOutput(ctx context.Context, peripheralAddress byte, outputNumber byte, duration time.Duration) error {
+	outputControl, err := NewOutputPulse(outputNumber, duration)
+	if err != nil {
+		return err
+	}
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, OutputCommand{*outputControl})
+	if err != nil {
+		return err
+	}
+	if reply.MessageCode == REPLY_OSTATR {
+		return checkOutputActive(ParseOutputStatus(reply.MessageData), outputNumber)
+	}
+	if err := expectReply(reply, REPLY_ACK); err != nil {
+		return err
+	}
+	if duration < outputStatusConfirmationWindow {
+		// The pulse may already be over by the time the status is read back
+		return nil
+	}
+
+	outputStatus, err := osdpMessenger.OutputStatus(ctx, peripheralAddress)
+	if err != nil {
+		return err
+	}
+	return checkOutputActive(outputStatus, outputNumber)
+}
+
+func checkOutputActive(outputStatus []bool, outputNumber byte) error {
+	if int(outputNumber) >= len(outputStatus) {
+		return ReplyDataLengthError
+	}
+	if !outputStatus[outputNumber] {
+		return OutputStateMismatchError
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package osdp
+
+import "context"
+
+type CapabilityFunctionCode byte
+
+const (
+	CapabilityContactStatusMonitoring    CapabilityFunctionCode = 0x01
+	CapabilityOutputControl              CapabilityFunctionCode = 0x02
+	CapabilityCardDataFormat             CapabilityFunctionCode = 0x03
+	CapabilityLEDControl                 CapabilityFunctionCode = 0x04
+	CapabilityAudibleOutput              CapabilityFunctionCode = 0x05
+	CapabilityTextOutput                 CapabilityFunctionCode = 0x06
+	CapabilityTimeKeeping                CapabilityFunctionCode = 0x07
+	CapabilityCheckCharacterSupport      CapabilityFunctionCode = 0x08
+	CapabilityCommunicationSecurity      CapabilityFunctionCode = 0x09
+	CapabilityReceiveBufferSize          CapabilityFunctionCode = 0x0A
+	CapabilityLargestCombinedMessageSize CapabilityFunctionCode = 0x0B
+	CapabilitySmartCardSupport           CapabilityFunctionCode = 0x0C
+	CapabilityReaders                    CapabilityFunctionCode = 0x0D
+	CapabilityBiometrics                 CapabilityFunctionCode = 0x0E
+)
+
+const (
+	capabilityRecordLength     int  = 3
+	standardCapabilitiesReport byte = 0x00
+	crcSupported               byte = 0x01
+	aes128Supported            byte = 0x01
+	transparentReaderSupported byte = 0x01
+	extendedPacketSupported    byte = 0x02
+)
+
+type PeripheralCapability struct {
+	FunctionCode  CapabilityFunctionCode
+	Compliance    byte
+	NumberOfItems byte
+}
+
+type PeripheralCapabilities struct {
+	Capabilities []PeripheralCapability
+}
+
+// OSDPCapabilityValidator is implemented by commands that can only be checked against what the PD reported in osdp_PDCAP
+type OSDPCapabilityValidator interface {
+	ValidateCapabilities(peripheralCapabilities *PeripheralCapabilities) error
+}
+
+func ParsePeripheralCapabilities(msgData []byte) (*PeripheralCapabilities, error) {
+	if len(msgData)%capabilityRecordLength != 0 {
+		return nil, ReplyDataLengthError
+	}
+	peripheralCapabilities := &PeripheralCapabilities{Capabilities: make([]PeripheralCapability, 0, len(msgData)/capabilityRecordLength)}
+	for i := 0; i < len(msgData); i += capabilityRecordLength {
+		peripheralCapabilities.Capabilities = append(peripheralCapabilities.Capabilities, PeripheralCapability{
+			FunctionCode:  CapabilityFunctionCode(msgData[i]),
+			Compliance:    msgData[i+1],
+			NumberOfItems: msgData[i+2],
+		})
+	}
+	return peripheralCapabilities, nil
+}
+
+func (peripheralCapabilities *PeripheralCapabilities) Capability(functionCode CapabilityFunctionCode) (PeripheralCapability, bool) {
+	for _, capability := range peripheralCapabilities.Capabilities {
+		if capability.FunctionCode == functionCode {
+			return capability, true
+		}
+	}
+	return PeripheralCapability{FunctionCode: functionCode}, false
+}
+
+func (peripheralCapabilities *PeripheralCapabilities) Inputs() int {
+	capability, _ := peripheralCapabilities.Capability(CapabilityContactStatusMonitoring)
+	return int(capability.NumberOfItems)
+}
+
+func (peripheralCapabilities *PeripheralCapabilities) Outputs() int {
+	capability, _ := peripheralCapabilities.Capability(CapabilityOutputControl)
+	return int(capability.NumberOfItems)
+}
+
+func (peripheralCapabilities *PeripheralCapabilities) Readers() int {
+	capability, _ := peripheralCapabilities.Capability(CapabilityReaders)
+	return int(capability.NumberOfItems)
+}
+
+func (peripheralCapabilities *PeripheralCapabilities) TextDisplaySize() TextDisplaySize {
+	capability, _ := peripheralCapabilities.Capability(CapabilityTextOutput)
+	return TextDisplaySizeFromCompliance(capability.Compliance)
+}
+
+func (peripheralCapabilities *PeripheralCapabilities) SupportsCRC() bool {
+	capability, _ := peripheralCapabilities.Capability(CapabilityCheckCharacterSupport)
+	return capability.Compliance&crcSupported == crcSupported
+}
+
+func (peripheralCapabilities *PeripheralCapabilities) SupportsSecureChannel() bool {
+	capability, _ := peripheralCapabilities.Capability(CapabilityCommunicationSecurity)
+	return capability.Compliance&aes128Supported == aes128Supported
+}
+
+func (peripheralCapabilities *PeripheralCapabilities) SupportsTransparentReader() bool {
+	capability, _ := peripheralCapabilities.Capability(CapabilitySmartCardSupport)
+	return capability.Compliance&transparentReaderSupported == transparentReaderSupported
+}
+
+func (peripheralCapabilities *PeripheralCapabilities) SupportsExtendedPacketMode() bool {
+	capability, _ := peripheralCapabilities.Capability(CapabilitySmartCardSupport)
+	return capability.Compliance&extendedPacketSupported == extendedPacketSupported
+}
+
+func (peripheralCapabilities *PeripheralCapabilities) SupportsBiometrics() bool {
+	capability, _ := peripheralCapabilities.Capability(CapabilityBiometrics)
+	return capability.Compliance != 0x00
+}
+
+// ReceiveBufferSize is the largest single message the PD can accept, 0 if not reported
+func (peripheralCapabilities *PeripheralCapabilities) ReceiveBufferSize() int {
+	capability, _ := peripheralCapabilities.Capability(CapabilityReceiveBufferSize)
+	return int(capability.Compliance) | int(capability.NumberOfItems)<<8
+}
+
+// LargestCombinedMessageSize is the largest multi-part message the PD can reassemble, 0 if not reported
+func (peripheralCapabilities *PeripheralCapabilities) LargestCombinedMessageSize() int {
+	capability, _ := peripheralCapabilities.Capability(CapabilityLargestCombinedMessageSize)
+	return int(capability.Compliance) | int(capability.NumberOfItems)<<8
+}
+
+// QueryCapabilities requests osdp_PDCAP and keeps the result for commands later sent to the PD
+func (osdpMessenger *OSDPMessenger) QueryCapabilities(ctx context.Context, peripheralAddress byte) (*PeripheralCapabilities, error) {
+	reply, err := osdpMessenger.sendAndReceiveContext(ctx, CMD_CAP, peripheralAddress, []byte{standardCapabilitiesReport})
+	if err != nil {
+		return nil, err
+	}
+	if err := expectReply(reply, REPLY_PDCAP); err != nil {
+		return nil, err
+	}
+	peripheralCapabilities, err := ParsePeripheralCapabilities(reply.MessageData)
+	if err != nil {
+		return nil, err
+	}
+	osdpMessenger.peripheral(peripheralAddress).capabilities = peripheralCapabilities
+	return peripheralCapabilities, nil
+}
+
+// Capabilities returns the capabilities last reported by a PD, or nil if they have not been queried
+func (osdpMessenger *OSDPMessenger) Capabilities(peripheralAddress byte) *PeripheralCapabilities {
+	state, ok := osdpMessenger.peripherals[peripheralAddress]
+	if !ok {
+		return nil
+	}
+	return state.capabilities
+}
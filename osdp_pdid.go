@@ -0,0 +1,61 @@
+package osdp
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	peripheralIdentificationLength int  = 12
+	standardIdentificationReport   byte = 0x00
+)
+
+// VendorCode is the IEEE assigned OUI of a manufacturer
+type VendorCode [3]byte
+
+func (vendorCode VendorCode) String() string {
+	return fmt.Sprintf("%02X-%02X-%02X", vendorCode[0], vendorCode[1], vendorCode[2])
+}
+
+type PeripheralIdentification struct {
+	VendorCode    VendorCode
+	ModelNumber   byte
+	Version       byte
+	SerialNumber  uint32
+	FirmwareMajor byte
+	FirmwareMinor byte
+	FirmwareBuild byte
+}
+
+func ParsePeripheralIdentification(msgData []byte) (*PeripheralIdentification, error) {
+	if len(msgData) < peripheralIdentificationLength {
+		return nil, ReplyDataLengthError
+	}
+	peripheralIdentification := &PeripheralIdentification{
+		ModelNumber:   msgData[3],
+		Version:       msgData[4],
+		SerialNumber:  binary.LittleEndian.Uint32(msgData[5:9]),
+		FirmwareMajor: msgData[9],
+		FirmwareMinor: msgData[10],
+		FirmwareBuild: msgData[11],
+	}
+	copy(peripheralIdentification.VendorCode[:], msgData[0:3])
+	return peripheralIdentification, nil
+}
+
+func (peripheralIdentification *PeripheralIdentification) FirmwareVersion() string {
+	return fmt.Sprintf("%d.%d.%d", peripheralIdentification.FirmwareMajor, peripheralIdentification.FirmwareMinor, peripheralIdentification.FirmwareBuild)
+}
+
+// Identify requests the standard osdp_PDID report. The vendor code and serial number identify a PD across address changes
+func (osdpMessenger *OSDPMessenger) Identify(ctx context.Context, peripheralAddress byte) (*PeripheralIdentification, error) {
+	reply, err := osdpMessenger.sendAndReceiveContext(ctx, CMD_ID, peripheralAddress, []byte{standardIdentificationReport})
+	if err != nil {
+		return nil, err
+	}
+	if err := expectReply(reply, REPLY_PDID); err != nil {
+		return nil, err
+	}
+	return ParsePeripheralIdentification(reply.MessageData)
+}
@@ -0,0 +1,331 @@
+package osdp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"io/ioutil"
+	"math/big"
+	"time"
+)
+
+// PIV data objects, SP 800-73-4 Table 3
+const (
+	PIVObjectDiscovery            uint32 = 0x00007E
+	PIVObjectCardAuthCertificate  uint32 = 0x5FC101
+	PIVObjectCHUID                uint32 = 0x5FC102
+	PIVObjectFingerprints         uint32 = 0x5FC103
+	PIVObjectAuthCertificate      uint32 = 0x5FC105
+	PIVObjectSecurityObject       uint32 = 0x5FC106
+	PIVObjectCapabilityContainer  uint32 = 0x5FC107
+	PIVObjectFacialImage          uint32 = 0x5FC108
+	PIVObjectPrintedInformation   uint32 = 0x5FC109
+	PIVObjectSignatureCertificate uint32 = 0x5FC10A
+	PIVObjectKeyMgmtCertificate   uint32 = 0x5FC10B
+)
+
+// Tags of the elements within a PIV data object
+const (
+	PIVElementFASCN       byte = 0x30
+	PIVElementGUID        byte = 0x34
+	PIVElementExpiration  byte = 0x35
+	PIVElementCertificate byte = 0x70
+	PIVElementCertInfo    byte = 0x71
+)
+
+type PIVAlgorithm byte
+
+const (
+	PIVAlgorithm3DES    PIVAlgorithm = 0x03
+	PIVAlgorithmRSA1024 PIVAlgorithm = 0x06
+	PIVAlgorithmRSA2048 PIVAlgorithm = 0x07
+	PIVAlgorithmAES128  PIVAlgorithm = 0x08
+	PIVAlgorithmAES192  PIVAlgorithm = 0x0A
+	PIVAlgorithmAES256  PIVAlgorithm = 0x0C
+	PIVAlgorithmECCP256 PIVAlgorithm = 0x11
+	PIVAlgorithmECCP384 PIVAlgorithm = 0x14
+)
+
+const (
+	PIVKeyAuthentication     byte = 0x9A
+	PIVKeyCardManagement     byte = 0x9B
+	PIVKeyDigitalSignature   byte = 0x9C
+	PIVKeyKeyManagement      byte = 0x9D
+	PIVKeyCardAuthentication byte = 0x9E
+)
+
+const (
+	pivObjectIDMax         uint32        = 0xFFFFFF
+	pivAuthHeaderLength    int           = 2
+	pivChallengeLength     int           = 32
+	osdpDefaultCardTimeout time.Duration = 5 * time.Second
+)
+
+// DigestInfo prefixes for PKCS #1 v1.5 signatures, RFC 8017 section 9.2
+var pkcs1DigestInfoPrefixes = map[crypto.Hash][]byte{
+	crypto.SHA256: {0x30, 0x31, 0x30, 0x0D, 0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x01, 0x05, 0x00, 0x04, 0x20},
+}
+
+// PIVDataCommand asks the reader for an element of a PIV data object from the card it holds, starting at Offset
+type PIVDataCommand struct {
+	ObjectID  uint32
+	ElementID byte
+	Offset    byte
+}
+
+// GeneralAuthenticateCommand passes a GENERAL AUTHENTICATE dynamic authentication template through to the card
+type GeneralAuthenticateCommand struct {
+	Algorithm    PIVAlgorithm
+	KeyReference byte
+	Template     []byte
+}
+
+// ChallengeResponseCommand asks the card to sign or encrypt Challenge with the referenced key,
+// the reader builds the GENERAL AUTHENTICATE template
+type ChallengeResponseCommand struct {
+	Algorithm    PIVAlgorithm
+	KeyReference byte
+	Challenge    []byte
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+func (pivDataCommand *PIVDataCommand) Code() OSDPCode {
+	return CMD_PIVDATA
+}
+
+func (pivDataCommand *PIVDataCommand) Validate() error {
+	if pivDataCommand.ObjectID > pivObjectIDMax {
+		return InvalidPIVObjectError
+	}
+	return nil
+}
+
+func (pivDataCommand *PIVDataCommand) ToBytes() []byte {
+	return []byte{
+		byte(pivDataCommand.ObjectID >> 16),
+		byte(pivDataCommand.ObjectID >> 8),
+		byte(pivDataCommand.ObjectID),
+		pivDataCommand.ElementID,
+		pivDataCommand.Offset,
+	}
+}
+
+func (generalAuthenticateCommand *GeneralAuthenticateCommand) Code() OSDPCode {
+	return CMD_GENAUTH
+}
+
+func (generalAuthenticateCommand *GeneralAuthenticateCommand) Validate() error {
+	if len(generalAuthenticateCommand.Template) == 0 {
+		return InvalidPIVAuthDataError
+	}
+	if pivAuthHeaderLength+len(generalAuthenticateCommand.Template) > 0xFFFF {
+		return MultiPartTooLargeError
+	}
+	return nil
+}
+
+// ToBytes frames the whole command as one multi-part fragment, which is what SendCommand and QueueCommand put on
+// the wire. GeneralAuthenticate splits a template too large for the PD receive buffer across several fragments
+func (generalAuthenticateCommand *GeneralAuthenticateCommand) ToBytes() []byte {
+	payload := generalAuthenticateCommand.payload()
+	return (&MultiPartFragment{WholeLength: uint16(len(payload)), Data: payload}).ToBytes()
+}
+
+func (generalAuthenticateCommand *GeneralAuthenticateCommand) payload() []byte {
+	payload := make([]byte, 0, pivAuthHeaderLength+len(generalAuthenticateCommand.Template))
+	payload = append(payload, byte(generalAuthenticateCommand.Algorithm), generalAuthenticateCommand.KeyReference)
+	return append(payload, generalAuthenticateCommand.Template...)
+}
+
+func (challengeResponseCommand *ChallengeResponseCommand) Code() OSDPCode {
+	return CMD_CRAUTH
+}
+
+func (challengeResponseCommand *ChallengeResponseCommand) Validate() error {
+	if len(challengeResponseCommand.Challenge) == 0 {
+		return InvalidPIVAuthDataError
+	}
+	if pivAuthHeaderLength+len(challengeResponseCommand.Challenge) > 0xFFFF {
+		return MultiPartTooLargeError
+	}
+	return nil
+}
+
+// ToBytes is a single fragment as for GeneralAuthenticateCommand, use ChallengeResponse for large challenges
+func (challengeResponseCommand *ChallengeResponseCommand) ToBytes() []byte {
+	payload := challengeResponseCommand.payload()
+	return (&MultiPartFragment{WholeLength: uint16(len(payload)), Data: payload}).ToBytes()
+}
+
+func (challengeResponseCommand *ChallengeResponseCommand) payload() []byte {
+	payload := make([]byte, 0, pivAuthHeaderLength+len(challengeResponseCommand.Challenge))
+	payload = append(payload, byte(challengeResponseCommand.Algorithm), challengeResponseCommand.KeyReference)
+	return append(payload, challengeResponseCommand.Challenge...)
+}
+
+// PIVData reads an element of a PIV data object, reassembling the osdp_PIVDATAR fragments
+func (osdpMessenger *OSDPMessenger) PIVData(ctx context.Context, peripheralAddress byte, pivDataCommand *PIVDataCommand) ([]byte, error) {
+	ctx, cancel := withDefaultTimeout(ctx, osdpDefaultCardTimeout)
+	defer cancel()
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, pivDataCommand)
+	if err != nil {
+		return nil, err
+	}
+	return osdpMessenger.awaitMultiPartReply(ctx, peripheralAddress, reply, REPLY_PIVDATAR)
+}
+
+func (osdpMessenger *OSDPMessenger) GeneralAuthenticate(ctx context.Context, peripheralAddress byte, generalAuthenticateCommand *GeneralAuthenticateCommand) ([]byte, error) {
+	ctx, cancel := withDefaultTimeout(ctx, osdpDefaultCardTimeout)
+	defer cancel()
+	if err := osdpMessenger.validateCommand(peripheralAddress, generalAuthenticateCommand); err != nil {
+		return nil, err
+	}
+	reply, err := osdpMessenger.sendMultiPart(ctx, CMD_GENAUTH, peripheralAddress, generalAuthenticateCommand.payload())
+	if err != nil {
+		return nil, err
+	}
+	return osdpMessenger.awaitMultiPartReply(ctx, peripheralAddress, reply, REPLY_GENAUTHR)
+}
+
+func (osdpMessenger *OSDPMessenger) ChallengeResponse(ctx context.Context, peripheralAddress byte, challengeResponseCommand *ChallengeResponseCommand) ([]byte, error) {
+	ctx, cancel := withDefaultTimeout(ctx, osdpDefaultCardTimeout)
+	defer cancel()
+	if err := osdpMessenger.validateCommand(peripheralAddress, challengeResponseCommand); err != nil {
+		return nil, err
+	}
+	reply, err := osdpMessenger.sendMultiPart(ctx, CMD_CRAUTH, peripheralAddress, challengeResponseCommand.payload())
+	if err != nil {
+		return nil, err
+	}
+	return osdpMessenger.awaitMultiPartReply(ctx, peripheralAddress, reply, REPLY_CRAUTHR)
+}
+
+// AuthenticateCardAuthKey performs the PKI-CAK check against the card presented to the PD: it reads the card
+// authentication certificate, has the card sign a random challenge with its card authentication key and
+// verifies the signature. Validating the certificate chain is left to the caller
+func (osdpMessenger *OSDPMessenger) AuthenticateCardAuthKey(ctx context.Context, peripheralAddress byte) (*x509.Certificate, error) {
+	certificateData, err := osdpMessenger.PIVData(ctx, peripheralAddress, &PIVDataCommand{ObjectID: PIVObjectCardAuthCertificate, ElementID: PIVElementCertificate})
+	if err != nil {
+		return nil, err
+	}
+	certificate, err := parsePIVCertificate(certificateData)
+	if err != nil {
+		return nil, err
+	}
+	challenge := make([]byte, pivChallengeLength)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+	challengeResponseCommand, hash, err := newCardAuthChallenge(certificate, challenge)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := osdpMessenger.ChallengeResponse(ctx, peripheralAddress, challengeResponseCommand)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyCardAuthSignature(certificate, hash, challenge, signature); err != nil {
+		return nil, err
+	}
+	return certificate, nil
+}
+
+func (osdpMessenger *OSDPMessenger) awaitMultiPartReply(ctx context.Context, peripheralAddress byte, reply *OSDPMessage, replyCode OSDPCode) ([]byte, error) {
+	var err error
+	if reply.MessageCode != replyCode {
+		if err := expectReply(reply, REPLY_ACK); err != nil {
+			return nil, err
+		}
+		reply, err = osdpMessenger.pollForReply(ctx, peripheralAddress, replyCode)
+		if err != nil {
+			return nil, osdpMessenger.abortOnCancel(ctx, peripheralAddress, err)
+		}
+	}
+	msgData, err := osdpMessenger.receiveMultiPart(ctx, peripheralAddress, reply)
+	return msgData, osdpMessenger.abortOnCancel(ctx, peripheralAddress, err)
+}
+
+// parsePIVCertificate accepts the certificate element as stored on the card, which may be gzip compressed
+func parsePIVCertificate(certificateData []byte) (*x509.Certificate, error) {
+	if bytes.HasPrefix(certificateData, []byte{0x1F, 0x8B}) {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(certificateData))
+		if err != nil {
+			return nil, err
+		}
+		certificateData, err = ioutil.ReadAll(gzipReader)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return x509.ParseCertificate(certificateData)
+}
+
+// newCardAuthChallenge builds the challenge for the card's key type. ECC keys sign the digest directly,
+// RSA keys perform a raw private key operation so the digest is sent PKCS #1 v1.5 padded
+func newCardAuthChallenge(certificate *x509.Certificate, challenge []byte) (*ChallengeResponseCommand, crypto.Hash, error) {
+	switch publicKey := certificate.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		switch publicKey.Curve {
+		case elliptic.P256():
+			digest := crypto.SHA256.New()
+			digest.Write(challenge)
+			return &ChallengeResponseCommand{Algorithm: PIVAlgorithmECCP256, KeyReference: PIVKeyCardAuthentication, Challenge: digest.Sum(nil)}, crypto.SHA256, nil
+		case elliptic.P384():
+			digest := crypto.SHA384.New()
+			digest.Write(challenge)
+			return &ChallengeResponseCommand{Algorithm: PIVAlgorithmECCP384, KeyReference: PIVKeyCardAuthentication, Challenge: digest.Sum(nil)}, crypto.SHA384, nil
+		}
+	case *rsa.PublicKey:
+		algorithm := PIVAlgorithmRSA2048
+		if publicKey.Size() == 128 {
+			algorithm = PIVAlgorithmRSA1024
+		} else if publicKey.Size() != 256 {
+			break
+		}
+		digest := crypto.SHA256.New()
+		digest.Write(challenge)
+		prefix := pkcs1DigestInfoPrefixes[crypto.SHA256]
+		block := make([]byte, publicKey.Size())
+		block[1] = 0x01
+		padding := len(block) - len(prefix) - crypto.SHA256.Size() - 1
+		for i := 2; i < padding; i++ {
+			block[i] = 0xFF
+		}
+		copy(block[padding+1:], prefix)
+		copy(block[padding+1+len(prefix):], digest.Sum(nil))
+		return &ChallengeResponseCommand{Algorithm: algorithm, KeyReference: PIVKeyCardAuthentication, Challenge: block}, crypto.SHA256, nil
+	}
+	return nil, 0, UnsupportedPIVKeyError
+}
+
+func verifyCardAuthSignature(certificate *x509.Certificate, hash crypto.Hash, challenge []byte, signature []byte) error {
+	digest := hash.New()
+	digest.Write(challenge)
+	switch publicKey := certificate.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		parsedSignature := &ecdsaSignature{}
+		if rest, err := asn1.Unmarshal(signature, parsedSignature); err != nil || len(rest) != 0 {
+			return CardAuthenticationError
+		}
+		if !ecdsa.Verify(publicKey, digest.Sum(nil), parsedSignature.R, parsedSignature.S) {
+			return CardAuthenticationError
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(publicKey, hash, digest.Sum(nil), signature); err != nil {
+			return CardAuthenticationError
+		}
+	default:
+		return UnsupportedPIVKeyError
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package osdp
+
+import (
+	"context"
+	"time"
+)
+
+const osdpDefaultPromptTimeout time.Duration = 30 * time.Second
+
+// PromptCommand shows Text on the reader and collects the cardholder's keypad entry. Timeout is in seconds,
+// the PD clears the prompt once it passes
+type PromptCommand struct {
+	ReaderNumber byte
+	Timeout      byte
+	Text         string
+}
+
+func (promptCommand *PromptCommand) Code() OSDPCode {
+	return CMD_PROMPT
+}
+
+func (promptCommand *PromptCommand) Validate() error {
+	return validateText(promptCommand.Text)
+}
+
+func (promptCommand *PromptCommand) ToBytes() []byte {
+	msgData := []byte{promptCommand.ReaderNumber, promptCommand.Timeout, byte(len(promptCommand.Text))}
+	return append(msgData, promptCommand.Text...)
+}
+
+// Prompt shows the prompt and polls the PD for keypad entry on its reader until terminatorKey is pressed or maxLength
+// keys have been entered. The context bounds the wait, defaulting to the prompt Timeout, and cancelling it aborts the prompt
+func (osdpMessenger *OSDPMessenger) Prompt(ctx context.Context, peripheralAddress byte, promptCommand *PromptCommand, maxLength int, terminatorKey byte) (*KeypadData, error) {
+	timeout := osdpDefaultPromptTimeout
+	if promptCommand.Timeout > 0 {
+		timeout = time.Duration(promptCommand.Timeout) * time.Second
+	}
+	ctx, cancel := withDefaultTimeout(ctx, timeout)
+	defer cancel()
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, promptCommand)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectReply(reply, REPLY_ACK); err != nil {
+		return nil, err
+	}
+	keys := []byte{}
+	for {
+		reply, err := osdpMessenger.pollForReply(ctx, peripheralAddress, REPLY_KEYPAD)
+		if err != nil {
+			return nil, osdpMessenger.abortOnCancel(ctx, peripheralAddress, err)
+		}
+		keypadData, err := ParseKeypadData(reply.MessageData)
+		if err != nil {
+			return nil, err
+		}
+		if keypadData.ReaderNumber != promptCommand.ReaderNumber {
+			osdpMessenger.queueReply(peripheralAddress, reply)
+			continue
+		}
+		for i := 0; i < len(keypadData.Keys); i++ {
+			if terminatorKey != 0 && keypadData.Keys[i] == terminatorKey {
+				return &KeypadData{ReaderNumber: promptCommand.ReaderNumber, Keys: string(keys)}, nil
+			}
+			keys = append(keys, keypadData.Keys[i])
+			if maxLength > 0 && len(keys) >= maxLength {
+				return &KeypadData{ReaderNumber: promptCommand.ReaderNumber, Keys: string(keys)}, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package osdp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RetryError is returned when a message still failed after being retransmitted, Err is the last failure
+type RetryError struct {
+	Retries uint32
+	Err     error
+}
+
+func (retryError *RetryError) Error() string {
+	return fmt.Sprintf("%v after %d retries", retryError.Err, retryError.Retries)
+}
+
+func (retryError *RetryError) Unwrap() error {
+	return retryError.Err
+}
+
+// SetRetryPolicy retransmits a message up to retries times, spacing apart, when the reply times out or fails its
+// check characters. A PD still failing after the last retry is marked offline. The typed helpers share the context
+// deadline between the attempts. The default is no retries
+func (osdpMessenger *OSDPMessenger) SetRetryPolicy(retries uint32, spacing time.Duration) {
+	osdpMessenger.retries = retries
+	osdpMessenger.retrySpacing = spacing
+}
+
+// retryable is true for failures where the PD did not get or could not check the frame, either way it was not acted on
+func retryable(reply *OSDPMessage, err error) bool {
+	if err != nil {
+		return err == OSDPReceiveTimeoutError || err == ChecksumFailedError
+	}
+	return errors.Is(reply.NakError(), NakBadCRCError)
+}
+
+// attemptTimeout is the read timeout of each attempt at an exchange bounded by ctx. The reply timeout is shortened
+// so that every attempt the retry policy allows, and the spacing between them, fits before the deadline
+func (osdpMessenger *OSDPMessenger) attemptTimeout(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return osdpMessenger.replyTimeout
+	}
+	retries := time.Duration(osdpMessenger.retries)
+	sharedTimeout := (time.Until(deadline) - retries*osdpMessenger.retrySpacing) / (retries + 1)
+	if sharedTimeout > 0 && sharedTimeout < osdpMessenger.replyTimeout {
+		return sharedTimeout
+	}
+	return osdpMessenger.replyTimeout
+}
+
+// exchangeWithRetries resends the identical frame, sequence number included, counting the retransmissions in osdpMessage.Retries.
+// A PD whose last attempt still timed out or failed its check characters is marked offline. The caller's context
+// ending is not the PD failing, so it leaves the PD online and its sequence untouched
+func (osdpMessenger *OSDPMessenger) exchangeWithRetries(ctx context.Context, osdpMessage *OSDPMessage, writeTimeout time.Duration, readTimeout time.Duration) (*OSDPMessage, error) {
+	var reply *OSDPMessage
+	var err error
+retransmit:
+	for osdpMessage.Retries = 0; ; osdpMessage.Retries++ {
+		reply, err = osdpMessenger.exchange(ctx, osdpMessage, writeTimeout, readTimeout)
+		if !retryable(reply, err) || osdpMessage.Retries >= osdpMessenger.retries {
+			break
+		}
+		if err == ChecksumFailedError {
+			osdpMessenger.transceiver.Reset()
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break retransmit
+		case <-time.After(osdpMessenger.retrySpacing):
+		}
+		osdpMessenger.metrics.Retransmissions++
+	}
+	if err == nil {
+		return reply, nil
+	}
+	if retryable(reply, err) {
+		osdpMessenger.setOffline(osdpMessage.PeripheralAddress)
+	}
+	if osdpMessage.Retries > 0 {
+		return nil, &RetryError{Retries: osdpMessage.Retries, Err: err}
+	}
+	return nil, err
+}
@@ -0,0 +1,154 @@
+package osdp
+
+import "context"
+
+// StatusRequest is one of the data-less status queries, osdp_LSTAT, osdp_ISTAT, osdp_OSTAT or osdp_RSTAT
+type StatusRequest OSDPCode
+
+const (
+	LocalStatusRequest  StatusRequest = StatusRequest(CMD_LSTAT)
+	InputStatusRequest  StatusRequest = StatusRequest(CMD_ISTAT)
+	OutputStatusRequest StatusRequest = StatusRequest(CMD_OSTAT)
+	ReaderStatusRequest StatusRequest = StatusRequest(CMD_RSTAT)
+)
+
+type ReaderTamperStatus byte
+
+const (
+	ReaderNormal       ReaderTamperStatus = 0x00
+	ReaderNotConnected ReaderTamperStatus = 0x01
+	ReaderTamper       ReaderTamperStatus = 0x02
+)
+
+const (
+	localStatusLength int  = 2
+	inputStatusActive byte = 0x01
+)
+
+func (statusRequest StatusRequest) Code() OSDPCode {
+	return OSDPCode(statusRequest)
+}
+
+func (statusRequest StatusRequest) Validate() error {
+	switch statusRequest {
+	case LocalStatusRequest, InputStatusRequest, OutputStatusRequest, ReaderStatusRequest:
+		return nil
+	}
+	return InvalidStatusRequestError
+}
+
+func (statusRequest StatusRequest) ToBytes() []byte {
+	return []byte{}
+}
+
+// ReplyCode is the reply a PD sends to the status request
+func (statusRequest StatusRequest) ReplyCode() OSDPCode {
+	switch statusRequest {
+	case LocalStatusRequest:
+		return REPLY_LSTATR
+	case InputStatusRequest:
+		return REPLY_ISTATR
+	case OutputStatusRequest:
+		return REPLY_OSTATR
+	}
+	return REPLY_RSTATR
+}
+
+type LocalStatus struct {
+	Tamper       bool
+	PowerFailure bool
+}
+
+type PhysicalStatus struct {
+	Local   *LocalStatus
+	Inputs  []bool
+	Outputs []bool
+	Readers []ReaderTamperStatus
+}
+
+func ParseLocalStatus(msgData []byte) (*LocalStatus, error) {
+	if len(msgData) != localStatusLength {
+		return nil, ReplyDataLengthError
+	}
+	return &LocalStatus{Tamper: msgData[0] != 0x00, PowerFailure: msgData[1] != 0x00}, nil
+}
+
+// ParseInputStatus decodes osdp_ISTATR, one entry per input with true meaning active
+func ParseInputStatus(msgData []byte) []bool {
+	inputStatus := make([]bool, len(msgData))
+	for i := range msgData {
+		inputStatus[i] = msgData[i] == inputStatusActive
+	}
+	return inputStatus
+}
+
+// ParseReaderStatus decodes osdp_RSTATR, one entry per external reader
+func ParseReaderStatus(msgData []byte) []ReaderTamperStatus {
+	readerStatus := make([]ReaderTamperStatus, len(msgData))
+	for i := range msgData {
+		readerStatus[i] = ReaderTamperStatus(msgData[i])
+	}
+	return readerStatus
+}
+
+func (osdpMessenger *OSDPMessenger) requestStatus(ctx context.Context, peripheralAddress byte, statusRequest StatusRequest) ([]byte, error) {
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, statusRequest)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectReply(reply, statusRequest.ReplyCode()); err != nil {
+		return nil, err
+	}
+	return reply.MessageData, nil
+}
+
+func (osdpMessenger *OSDPMessenger) LocalStatus(ctx context.Context, peripheralAddress byte) (*LocalStatus, error) {
+	msgData, err := osdpMessenger.requestStatus(ctx, peripheralAddress, LocalStatusRequest)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLocalStatus(msgData)
+}
+
+func (osdpMessenger *OSDPMessenger) InputStatus(ctx context.Context, peripheralAddress byte) ([]bool, error) {
+	msgData, err := osdpMessenger.requestStatus(ctx, peripheralAddress, InputStatusRequest)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInputStatus(msgData), nil
+}
+
+func (osdpMessenger *OSDPMessenger) OutputStatus(ctx context.Context, peripheralAddress byte) ([]bool, error) {
+	msgData, err := osdpMessenger.requestStatus(ctx, peripheralAddress, OutputStatusRequest)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOutputStatus(msgData), nil
+}
+
+func (osdpMessenger *OSDPMessenger) ReaderStatus(ctx context.Context, peripheralAddress byte) ([]ReaderTamperStatus, error) {
+	msgData, err := osdpMessenger.requestStatus(ctx, peripheralAddress, ReaderStatusRequest)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReaderStatus(msgData), nil
+}
+
+// PhysicalStatus queries local, input, output and reader status in turn
+func (osdpMessenger *OSDPMessenger) PhysicalStatus(ctx context.Context, peripheralAddress byte) (*PhysicalStatus, error) {
+	var err error
+	physicalStatus := &PhysicalStatus{}
+	if physicalStatus.Local, err = osdpMessenger.LocalStatus(ctx, peripheralAddress); err != nil {
+		return nil, err
+	}
+	if physicalStatus.Inputs, err = osdpMessenger.InputStatus(ctx, peripheralAddress); err != nil {
+		return nil, err
+	}
+	if physicalStatus.Outputs, err = osdpMessenger.OutputStatus(ctx, peripheralAddress); err != nil {
+		return nil, err
+	}
+	if physicalStatus.Readers, err = osdpMessenger.ReaderStatus(ctx, peripheralAddress); err != nil {
+		return nil, err
+	}
+	return physicalStatus, nil
+}
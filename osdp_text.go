@@ -0,0 +1,114 @@
+package osdp
+
+type TextCommandCode byte
+
+const (
+	TextPermanentNoWrap TextCommandCode = 0x01
+	TextPermanentWrap   TextCommandCode = 0x02
+	TextTemporaryNoWrap TextCommandCode = 0x03
+	TextTemporaryWrap   TextCommandCode = 0x04
+)
+
+const (
+	maxTextLength        int  = 0xFF
+	minPrintableASCII    byte = 0x20
+	maxPrintableASCII    byte = 0x7E
+	defaultDisplayColumn int  = 16
+)
+
+type TextDisplaySize struct {
+	Rows    int
+	Columns int
+}
+
+// TextDisplaySizeFromCompliance maps the compliance level of the osdp_PDCAP text output function to a display size
+func TextDisplaySizeFromCompliance(compliance byte) TextDisplaySize {
+	switch compliance {
+	case 0x01:
+		return TextDisplaySize{Rows: 1, Columns: defaultDisplayColumn}
+	case 0x02:
+		return TextDisplaySize{Rows: 2, Columns: defaultDisplayColumn}
+	case 0x03:
+		return TextDisplaySize{Rows: 4, Columns: defaultDisplayColumn}
+	}
+	return TextDisplaySize{}
+}
+
+// TextCommand displays Text starting at Row and Column, both counted from 1. TemporaryTime is in seconds
+type TextCommand struct {
+	ReaderNumber  byte
+	CommandCode   TextCommandCode
+	TemporaryTime byte
+	Row           byte
+	Column        byte
+	Text          string
+}
+
+func (textCommand *TextCommand) Code() OSDPCode {
+	return CMD_TEXT
+}
+
+func (textCommand *TextCommand) Validate() error {
+	if textCommand.CommandCode < TextPermanentNoWrap || textCommand.CommandCode > TextTemporaryWrap {
+		return InvalidTextCommandError
+	}
+	if textCommand.Row == 0 || textCommand.Column == 0 {
+		return InvalidTextPositionError
+	}
+	return validateText(textCommand.Text)
+}
+
+func validateText(text string) error {
+	if len(text) > maxTextLength {
+		return TextTooLongError
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] < minPrintableASCII || text[i] > maxPrintableASCII {
+			return InvalidTextCharacterError
+		}
+	}
+	return nil
+}
+
+// ValidateDisplay checks that the text fits on a display of the given size from its starting position
+func (textCommand *TextCommand) ValidateDisplay(displaySize TextDisplaySize) error {
+	if err := textCommand.Validate(); err != nil {
+		return err
+	}
+	row := int(textCommand.Row)
+	column := int(textCommand.Column)
+	if row > displaySize.Rows || column > displaySize.Columns {
+		return InvalidTextPositionError
+	}
+	available := displaySize.Columns - column + 1
+	if textCommand.CommandCode == TextPermanentWrap || textCommand.CommandCode == TextTemporaryWrap {
+		available += (displaySize.Rows - row) * displaySize.Columns
+	}
+	if len(textCommand.Text) > available {
+		return TextTooLongError
+	}
+	return nil
+}
+
+func (textCommand *TextCommand) ValidateCapabilities(peripheralCapabilities *PeripheralCapabilities) error {
+	if capability, ok := peripheralCapabilities.Capability(CapabilityTextOutput); !ok || capability.Compliance == 0x00 {
+		return CapabilityUnsupportedError
+	}
+	return textCommand.ValidateDisplay(peripheralCapabilities.TextDisplaySize())
+}
+
+func (textCommand *TextCommand) ToBytes() []byte {
+	msgData := []byte{
+		textCommand.ReaderNumber, byte(textCommand.CommandCode), textCommand.TemporaryTime,
+		textCommand.Row, textCommand.Column, byte(len(textCommand.Text)),
+	}
+	return append(msgData, textCommand.Text...)
+}
+
+func TextPresentCard(readerNumber byte) *TextCommand {
+	return &TextCommand{ReaderNumber: readerNumber, CommandCode: TextPermanentNoWrap, Row: 1, Column: 1, Text: "Present card"}
+}
+
+func TextEnterPIN(readerNumber byte) *TextCommand {
+	return &TextCommand{ReaderNumber: readerNumber, CommandCode: TextPermanentNoWrap, Row: 1, Column: 1, Text: "Enter PIN"}
+}
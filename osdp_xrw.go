@@ -0,0 +1,294 @@
+package osdp
+
+import "context"
+
+type TransparentMode byte
+
+const (
+	TransparentModeDefault  TransparentMode = 0x00
+	TransparentModeExtended TransparentMode = 0x01
+)
+
+// Profile commands of osdp_XWR, Mode-00 commands are accepted in either mode
+const (
+	XWRReadModeSetting   byte = 0x01
+	XWRSetModeSetting    byte = 0x02
+	XWRTransparentSend   byte = 0x01
+	XWRConnectionDone    byte = 0x02
+	XWRSecurePINEntry    byte = 0x03
+	XWRSmartCardScan     byte = 0x04
+	XRDModeSettingReport byte = 0x01
+	XRDCardInfoReport    byte = 0x02
+	XRDTransparentData   byte = 0x01
+	XRDSecurePINComplete byte = 0x02
+	XRDCardPresent       byte = 0x03
+)
+
+const (
+	extendedReadHeaderLength int = 2
+	transparentHeaderLength  int = 2
+	cardInfoHeaderLength     int = 4
+	// CLA, INS, P1 and P2
+	apduHeaderLength int = 4
+)
+
+// ExtendedWriteCommand is an osdp_XWR command, Data is the profile specific payload after the profile command
+type ExtendedWriteCommand struct {
+	Profile TransparentMode
+	Command byte
+	Data    []byte
+}
+
+// ExtendedReadReply is an osdp_XRD reply, Data is the profile specific payload after the profile reply code
+type ExtendedReadReply struct {
+	Profile TransparentMode
+	Reply   byte
+	Data    []byte
+}
+
+type TransparentModeSetting struct {
+	Mode            TransparentMode
+	CardInfoReports bool
+}
+
+// TransparentCardInfo is reported in Mode-00 when card info reports are enabled and a card is presented
+type TransparentCardInfo struct {
+	ReaderNumber byte
+	Protocol     byte
+	CSN          []byte
+	ProtocolData []byte
+}
+
+type TransparentCardPresent struct {
+	ReaderNumber byte
+	Status       byte
+}
+
+// TransparentResponse carries the card's response APDU, including the trailing status words
+type TransparentResponse struct {
+	ReaderNumber byte
+	Status       byte
+	Data         []byte
+}
+
+func NewReadTransparentMode() *ExtendedWriteCommand {
+	return &ExtendedWriteCommand{Profile: TransparentModeDefault, Command: XWRReadModeSetting}
+}
+
+func NewSetTransparentMode(transparentMode TransparentMode, cardInfoReports bool) *ExtendedWriteCommand {
+	config := byte(0x00)
+	if cardInfoReports {
+		config = 0x01
+	}
+	return &ExtendedWriteCommand{Profile: TransparentModeDefault, Command: XWRSetModeSetting, Data: []byte{byte(transparentMode), config}}
+}
+
+func NewTransparentSend(readerNumber byte, apdu []byte) *ExtendedWriteCommand {
+	return &ExtendedWriteCommand{Profile: TransparentModeExtended, Command: XWRTransparentSend, Data: append([]byte{readerNumber}, apdu...)}
+}
+
+func NewTransparentDone(readerNumber byte) *ExtendedWriteCommand {
+	return &ExtendedWriteCommand{Profile: TransparentModeExtended, Command: XWRConnectionDone, Data: []byte{readerNumber}}
+}
+
+func NewSmartCardScan(readerNumber byte) *ExtendedWriteCommand {
+	return &ExtendedWriteCommand{Profile: TransparentModeExtended, Command: XWRSmartCardScan, Data: []byte{readerNumber}}
+}
+
+func (extendedWriteCommand *ExtendedWriteCommand) Code() OSDPCode {
+	return CMD_XWR
+}
+
+func (extendedWriteCommand *ExtendedWriteCommand) Validate() error {
+	if extendedWriteCommand.Profile == TransparentModeExtended && extendedWriteCommand.Command == XWRTransparentSend && len(extendedWriteCommand.Data) < 1+apduHeaderLength {
+		return InvalidAPDUError
+	}
+	return nil
+}
+
+func (extendedWriteCommand *ExtendedWriteCommand) ValidateCapabilities(peripheralCapabilities *PeripheralCapabilities) error {
+	if !peripheralCapabilities.SupportsTransparentReader() {
+		return CapabilityUnsupportedError
+	}
+	return nil
+}
+
+func (extendedWriteCommand *ExtendedWriteCommand) ToBytes() []byte {
+	msgData := make([]byte, 0, extendedReadHeaderLength+len(extendedWriteCommand.Data))
+	msgData = append(msgData, byte(extendedWriteCommand.Profile), extendedWriteCommand.Command)
+	return append(msgData, extendedWriteCommand.Data...)
+}
+
+func ParseExtendedReadReply(msgData []byte) (*ExtendedReadReply, error) {
+	if len(msgData) < extendedReadHeaderLength {
+		return nil, ReplyDataLengthError
+	}
+	return &ExtendedReadReply{Profile: TransparentMode(msgData[0]), Reply: msgData[1], Data: msgData[extendedReadHeaderLength:]}, nil
+}
+
+func (extendedReadReply *ExtendedReadReply) is(profile TransparentMode, reply byte) bool {
+	return extendedReadReply.Profile == profile && extendedReadReply.Reply == reply
+}
+
+func ParseTransparentModeSetting(extendedReadReply *ExtendedReadReply) (*TransparentModeSetting, error) {
+	if !extendedReadReply.is(TransparentModeDefault, XRDModeSettingReport) {
+		return nil, UnexpectedReplyError
+	}
+	if len(extendedReadReply.Data) < 2 {
+		return nil, ReplyDataLengthError
+	}
+	return &TransparentModeSetting{Mode: TransparentMode(extendedReadReply.Data[0]), CardInfoReports: extendedReadReply.Data[1] == 0x01}, nil
+}
+
+func ParseTransparentCardInfo(extendedReadReply *ExtendedReadReply) (*TransparentCardInfo, error) {
+	if !extendedReadReply.is(TransparentModeDefault, XRDCardInfoReport) {
+		return nil, UnexpectedReplyError
+	}
+	msgData := extendedReadReply.Data
+	if len(msgData) < cardInfoHeaderLength {
+		return nil, ReplyDataLengthError
+	}
+	csnLength := int(msgData[2])
+	protocolDataLength := int(msgData[3])
+	if len(msgData)-cardInfoHeaderLength < csnLength+protocolDataLength {
+		return nil, ReplyDataLengthError
+	}
+	return &TransparentCardInfo{
+		ReaderNumber: msgData[0],
+		Protocol:     msgData[1],
+		CSN:          msgData[cardInfoHeaderLength : cardInfoHeaderLength+csnLength],
+		ProtocolData: msgData[cardInfoHeaderLength+csnLength : cardInfoHeaderLength+csnLength+protocolDataLength],
+	}, nil
+}
+
+func ParseTransparentCardPresent(extendedReadReply *ExtendedReadReply) (*TransparentCardPresent, error) {
+	if !extendedReadReply.is(TransparentModeExtended, XRDCardPresent) {
+		return nil, UnexpectedReplyError
+	}
+	if len(extendedReadReply.Data) < transparentHeaderLength {
+		return nil, ReplyDataLengthError
+	}
+	return &TransparentCardPresent{ReaderNumber: extendedReadReply.Data[0], Status: extendedReadReply.Data[1]}, nil
+}
+
+func ParseTransparentResponse(extendedReadReply *ExtendedReadReply) (*TransparentResponse, error) {
+	if !extendedReadReply.is(TransparentModeExtended, XRDTransparentData) {
+		return nil, UnexpectedReplyError
+	}
+	if len(extendedReadReply.Data) < transparentHeaderLength {
+		return nil, ReplyDataLengthError
+	}
+	return &TransparentResponse{
+		ReaderNumber: extendedReadReply.Data[0],
+		Status:       extendedReadReply.Data[1],
+		Data:         extendedReadReply.Data[transparentHeaderLength:],
+	}, nil
+}
+
+func (osdpMessenger *OSDPMessenger) TransparentMode(ctx context.Context, peripheralAddress byte) (*TransparentModeSetting, error) {
+	extendedReadReply, err := osdpMessenger.sendExtendedWrite(ctx, peripheralAddress, NewReadTransparentMode(), TransparentModeDefault, XRDModeSettingReport)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTransparentModeSetting(extendedReadReply)
+}
+
+// SetTransparentMode switches the PD between Mode-00 and the transparent Mode-01,
+// PDs that report their new mode setting instead of acknowledging are checked against it
+func (osdpMessenger *OSDPMessenger) SetTransparentMode(ctx context.Context, peripheralAddress byte, transparentMode TransparentMode, cardInfoReports bool) error {
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, NewSetTransparentMode(transparentMode, cardInfoReports))
+	if err != nil {
+		return err
+	}
+	if reply.MessageCode != REPLY_XRD {
+		return expectReply(reply, REPLY_ACK)
+	}
+	extendedReadReply, err := ParseExtendedReadReply(reply.MessageData)
+	if err != nil {
+		return err
+	}
+	transparentModeSetting, err := ParseTransparentModeSetting(extendedReadReply)
+	if err != nil {
+		return err
+	}
+	if transparentModeSetting.Mode != transparentMode {
+		return UnexpectedReplyError
+	}
+	return nil
+}
+
+// WaitForCard polls the PD until a card present notification arrives or the context is done
+func (osdpMessenger *OSDPMessenger) WaitForCard(ctx context.Context, peripheralAddress byte) (*TransparentCardPresent, error) {
+	extendedReadReply, err := osdpMessenger.pollForExtendedRead(ctx, peripheralAddress, TransparentModeExtended, XRDCardPresent)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTransparentCardPresent(extendedReadReply)
+}
+
+// ScanSmartCard asks the PD to look for a card on the reader and waits for its card present notification
+func (osdpMessenger *OSDPMessenger) ScanSmartCard(ctx context.Context, peripheralAddress byte, readerNumber byte) (*TransparentCardPresent, error) {
+	extendedReadReply, err := osdpMessenger.sendExtendedWrite(ctx, peripheralAddress, NewSmartCardScan(readerNumber), TransparentModeExtended, XRDCardPresent)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTransparentCardPresent(extendedReadReply)
+}
+
+// SendAPDU passes a command APDU through to the card and returns its response APDU
+func (osdpMessenger *OSDPMessenger) SendAPDU(ctx context.Context, peripheralAddress byte, readerNumber byte, apdu []byte) (*TransparentResponse, error) {
+	extendedReadReply, err := osdpMessenger.sendExtendedWrite(ctx, peripheralAddress, NewTransparentSend(readerNumber, apdu), TransparentModeExtended, XRDTransparentData)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTransparentResponse(extendedReadReply)
+}
+
+// TransparentDone tells the PD the ACU has finished with the card so it can release the connection
+func (osdpMessenger *OSDPMessenger) TransparentDone(ctx context.Context, peripheralAddress byte, readerNumber byte) error {
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, NewTransparentDone(readerNumber))
+	if err != nil {
+		return err
+	}
+	if reply.MessageCode == REPLY_XRD {
+		return nil
+	}
+	return expectReply(reply, REPLY_ACK)
+}
+
+// sendExtendedWrite sends an osdp_XWR and returns the matching osdp_XRD, polling for it when the PD only acknowledges the command
+func (osdpMessenger *OSDPMessenger) sendExtendedWrite(ctx context.Context, peripheralAddress byte, extendedWriteCommand *ExtendedWriteCommand, profile TransparentMode, replyCode byte) (*ExtendedReadReply, error) {
+	reply, err := osdpMessenger.SendCommand(ctx, peripheralAddress, extendedWriteCommand)
+	if err != nil {
+		return nil, err
+	}
+	if reply.MessageCode == REPLY_XRD {
+		extendedReadReply, err := ParseExtendedReadReply(reply.MessageData)
+		if err != nil {
+			return nil, err
+		}
+		if extendedReadReply.is(profile, replyCode) {
+			return extendedReadReply, nil
+		}
+	} else if err := expectReply(reply, REPLY_ACK); err != nil {
+		return nil, err
+	}
+	return osdpMessenger.pollForExtendedRead(ctx, peripheralAddress, profile, replyCode)
+}
+
+func (osdpMessenger *OSDPMessenger) pollForExtendedRead(ctx context.Context, peripheralAddress byte, profile TransparentMode, replyCode byte) (*ExtendedReadReply, error) {
+	for {
+		reply, err := osdpMessenger.pollForReply(ctx, peripheralAddress, REPLY_XRD)
+		if err != nil {
+			return nil, osdpMessenger.abortOnCancel(ctx, peripheralAddress, err)
+		}
+		extendedReadReply, err := ParseExtendedReadReply(reply.MessageData)
+		if err != nil {
+			return nil, err
+		}
+		if extendedReadReply.is(profile, replyCode) {
+			return extendedReadReply, nil
+		}
+		osdpMessenger.queueReply(peripheralAddress, reply)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	osdp "github.com/verkada/go-osdp"
+)
+
+func TestMultiPartSplitAndAssemble(t *testing.T) {
+	data := make([]byte, 250)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	fragments, err := osdp.SplitMultiPart(data, 100)
+	if err != nil {
+		t.Errorf("Unable to Split Multi-Part Message: %v", err)
+		return
+	}
+	require.Equal(t, 3, len(fragments))
+	require.Equal(t, []byte{0xFA, 0x00, 0xC8, 0x00, 0x32, 0x00}, fragments[2].ToBytes()[:6])
+
+	multiPartAssembler := &osdp.MultiPartAssembler{}
+	for i, fragment := range fragments {
+		parsedFragment, err := osdp.ParseMultiPartFragment(fragment.ToBytes())
+		if err != nil {
+			t.Errorf("Unable to Parse Multi-Part Fragment: %v", err)
+			return
+		}
+		complete, err := multiPartAssembler.Add(parsedFragment)
+		require.Equal(t, nil, err)
+		require.Equal(t, i == len(fragments)-1, complete)
+	}
+	require.Equal(t, data, multiPartAssembler.Data())
+
+	_, err = (&osdp.MultiPartAssembler{}).Add(fragments[1])
+	require.Equal(t, osdp.MultiPartSequenceError, err)
+}
+
+func TestBioReadMultiPart(t *testing.T) {
+	template := make([]byte, 100)
+	for i := range template {
+		template[i] = byte(0xFF - i)
+	}
+	bioReadReply := append([]byte{0x00, byte(osdp.BioStatusSuccess), byte(osdp.BioTypeRightIndex), 0x50, 0x64, 0x00}, template...)
+	fragments, err := osdp.SplitMultiPart(bioReadReply, 40)
+	if err != nil {
+		t.Errorf("Unable to Split Multi-Part Message: %v", err)
+		return
+	}
+	pdcap := []byte{0x0A, 0x40, 0x00, 0x0B, 0x00, 0x04, 0x0E, 0x01, 0x00}
+	cardRead := []byte{0x01, 0x01, 0x08, 0x00, 0xA5}
+
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		switch osdp.OSDPCode(osdpPacket.GetMessageCode()) {
+		case osdp.CMD_CAP:
+			return replyPacket(osdpPacket, osdp.REPLY_PDCAP, pdcap)
+		case osdp.CMD_BIOREAD:
+			return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+		case osdp.CMD_POLL:
+			// A card is presented to the PD's other reader during the scan
+			if cardRead != nil {
+				reply := replyPacket(osdpPacket, osdp.REPLY_RAW, cardRead)
+				cardRead = nil
+				return reply
+			}
+			fragment := fragments[0]
+			fragments = fragments[1:]
+			return replyPacket(osdpPacket, osdp.REPLY_BIOREADR, fragment.ToBytes())
+		}
+		return nil
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+	_, err = messenger.QueryCapabilities(context.Background(), 0x01)
+	if err != nil {
+		t.Errorf("Unable to Query Capabilities: %v", err)
+		return
+	}
+
+	result, err := messenger.BioRead(context.Background(), 0x01, &osdp.BioReadCommand{ReaderNumber: 0x00, Type: osdp.BioTypeRightIndex})
+	if err != nil {
+		t.Errorf("Unable to Read Biometric: %v", err)
+		return
+	}
+	require.Equal(t, &osdp.BioReadResult{ReaderNumber: 0x00, Status: osdp.BioStatusSuccess, Type: osdp.BioTypeRightIndex, Quality: 0x50, Template: template}, result)
+	require.Equal(t, 0, len(fragments))
+
+	require.Equal(t, 1, messenger.PendingReplies(0x01))
+	osdpReply := messenger.NextReply(0x01)
+	require.Equal(t, osdp.REPLY_RAW, osdpReply.MessageCode)
+	require.Equal(t, []byte{0x01, 0x01, 0x08, 0x00, 0xA5}, osdpReply.MessageData)
+	require.Nil(t, messenger.NextReply(0x01))
+}
+
+func TestBioMatch(t *testing.T) {
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_BIOMATCHR, []byte{0x00, 0x00, 0xC8})
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	bioMatchCommand := &osdp.BioMatchCommand{ReaderNumber: 0x00, Type: osdp.BioTypeRightIndex, Format: osdp.BioFormatANSI378, QualityThreshold: 0x50, Template: []byte{0x01, 0x02}}
+	result, err := messenger.BioMatch(context.Background(), 0x01, bioMatchCommand)
+	if err != nil {
+		t.Errorf("Unable to Match Biometric: %v", err)
+		return
+	}
+	require.Equal(t, &osdp.BioMatchResult{ReaderNumber: 0x00, Status: osdp.BioStatusSuccess, Score: 0xC8}, result)
+	require.Equal(t, []byte{0x00, 0x02, 0x02, 0x50, 0x02, 0x00, 0x01, 0x02}, transceiver.transmitted[0].GetMessageData())
+
+	bioMatchCommand.Template = nil
+	_, err = messenger.BioMatch(context.Background(), 0x01, bioMatchCommand)
+	require.Equal(t, osdp.InvalidBioTemplateError, err)
+}
+
+func TestBioWithoutFragmentation(t *testing.T) {
+	pdcap := []byte{0x0A, 0x40, 0x00, 0x0B, 0x00, 0x04, 0x0E, 0x01, 0x00}
+	fragmented := false
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		switch osdp.OSDPCode(osdpPacket.GetMessageCode()) {
+		case osdp.CMD_CAP:
+			return replyPacket(osdpPacket, osdp.REPLY_PDCAP, pdcap)
+		case osdp.CMD_BIOREAD:
+			return replyPacket(osdpPacket, osdp.REPLY_BIOREADR, []byte{0x00, 0x00, 0x02, 0x50, 0x02, 0x00, 0xAA, 0xBB})
+		case osdp.CMD_BIOMATCH:
+			msgData := osdpPacket.GetMessageData()
+			if len(msgData) > 0x40 {
+				return replyPacket(osdpPacket, osdp.REPLY_NAK, []byte{osdp.ERR_BAD_LEN})
+			}
+			if fragmented {
+				fragment, err := osdp.ParseMultiPartFragment(msgData)
+				require.Equal(t, nil, err)
+				if int(fragment.Offset)+len(fragment.Data) < int(fragment.WholeLength) {
+					return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+				}
+			}
+			return replyPacket(osdpPacket, osdp.REPLY_BIOMATCHR, []byte{0x00, 0x00, 0xC8})
+		}
+		return nil
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+	_, err := messenger.QueryCapabilities(context.Background(), 0x01)
+	require.Equal(t, nil, err)
+
+	// A PD able to fragment sends a short template whole
+	result, err := messenger.BioRead(context.Background(), 0x01, &osdp.BioReadCommand{ReaderNumber: 0x00, Type: osdp.BioTypeRightIndex})
+	require.Equal(t, nil, err)
+	require.Equal(t, []byte{0xAA, 0xBB}, result.Template)
+
+	bioMatchCommand := &osdp.BioMatchCommand{ReaderNumber: 0x00, Type: osdp.BioTypeRightIndex, Template: []byte{0x01, 0x02}}
+	_, err = messenger.BioMatch(context.Background(), 0x01, bioMatchCommand)
+	require.Equal(t, nil, err)
+	require.Equal(t, []byte{0x00, 0x02, 0x00, 0x00, 0x02, 0x00, 0x01, 0x02}, transceiver.transmitted[len(transceiver.transmitted)-1].GetMessageData())
+
+	// Only a template too large for the 64 byte receive buffer is fragmented
+	transmitted := len(transceiver.transmitted)
+	fragmented = true
+	bioMatchCommand.Template = make([]byte, 100)
+	_, err = messenger.BioMatch(context.Background(), 0x01, bioMatchCommand)
+	require.Equal(t, nil, err)
+	require.Equal(t, 3, len(transceiver.transmitted)-transmitted)
+}
+
+func TestBioReadCancelSendsAbort(t *testing.T) {
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(120 * time.Millisecond)
+		cancel()
+	}()
+	_, err := messenger.BioRead(ctx, 0x01, &osdp.BioReadCommand{ReaderNumber: 0x00, Type: osdp.BioTypeRightIndex})
+	require.Equal(t, context.Canceled, err)
+	lastPacket := transceiver.transmitted[len(transceiver.transmitted)-1]
+	require.Equal(t, osdp.CMD_ABORT, osdp.OSDPCode(lastPacket.GetMessageCode()))
+	require.Equal(t, osdp.CMD_POLL, osdp.OSDPCode(transceiver.transmitted[1].GetMessageCode()))
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	osdp "github.com/verkada/go-osdp"
+)
+
+func TestRawCardDataDecode(t *testing.T) {
+	registry := osdp.NewStandardCardFormatRegistry()
+	testCases := []struct {
+		msgData    []byte
+		credential *osdp.CardCredential
+	}{
+		{
+			msgData:    []byte{0x00, 0x01, 0x1A, 0x00, 0x80, 0x80, 0x00, 0x80},
+			credential: &osdp.CardCredential{Format: "H10301", FacilityCode: 1, CardNumber: 1},
+		},
+		{
+			msgData:    []byte{0x00, 0x01, 0x23, 0x00, 0xC1, 0x90, 0x0C, 0x0E, 0x40},
+			credential: &osdp.CardCredential{Format: "Corporate1000-35", FacilityCode: 100, CardNumber: 12345},
+		},
+		{
+			msgData:    []byte{0x00, 0x01, 0x25, 0x00, 0x82, 0x69, 0x0D, 0xDD, 0x50},
+			credential: &osdp.CardCredential{Format: "H10304", FacilityCode: 1234, CardNumber: 56789},
+		},
+	}
+	for _, testCase := range testCases {
+		rawCardData, err := osdp.ParseRawCardData(testCase.msgData)
+		if err != nil {
+			t.Errorf("Unable to Parse Raw Card Data: %v", err)
+			return
+		}
+		credential, err := registry.Decode(rawCardData)
+		if err != nil {
+			t.Errorf("Unable to Decode Raw Card Data: %v", err)
+			return
+		}
+		require.Equal(t, testCase.credential, credential)
+	}
+}
+
+func TestRawCardDataDecodeErrors(t *testing.T) {
+	registry := osdp.NewStandardCardFormatRegistry()
+
+	// Leading parity bit flipped
+	rawCardData, err := osdp.ParseRawCardData([]byte{0x00, 0x01, 0x1A, 0x00, 0x00, 0x80, 0x00, 0x80})
+	if err != nil {
+		t.Errorf("Unable to Parse Raw Card Data: %v", err)
+		return
+	}
+	_, err = registry.Decode(rawCardData)
+	require.Equal(t, osdp.CardParityError, err)
+
+	rawCardData, err = osdp.ParseRawCardData([]byte{0x00, 0x01, 0x08, 0x00, 0xFF})
+	if err != nil {
+		t.Errorf("Unable to Parse Raw Card Data: %v", err)
+		return
+	}
+	_, err = registry.Decode(rawCardData)
+	require.Equal(t, osdp.UnknownCardFormatError, err)
+
+	_, err = osdp.ParseRawCardData([]byte{0x00, 0x01, 0x1A, 0x00, 0x80})
+	require.Equal(t, osdp.ReplyDataLengthError, err)
+}
+
+func TestRawCardDataEncodeRoundTrip(t *testing.T) {
+	registry := osdp.NewStandardCardFormatRegistry()
+	cardFormats := []osdp.CardFormat{
+		osdp.CardFormatH10301, osdp.CardFormatH10306, osdp.CardFormatH10304,
+		osdp.CardFormatCorporate1000_35, osdp.CardFormatCorporate1000_48,
+	}
+	for _, cardFormat := range cardFormats {
+		rawCardData, err := osdp.NewRawCardData(0x01, cardFormat, 123, 4567)
+		if err != nil {
+			t.Errorf("Unable to Encode %v: %v", cardFormat.Name(), err)
+			return
+		}
+		parsedCardData, err := osdp.ParseRawCardData(rawCardData.ToBytes())
+		if err != nil {
+			t.Errorf("Unable to Parse %v: %v", cardFormat.Name(), err)
+			return
+		}
+		require.Equal(t, rawCardData, parsedCardData)
+		credential, err := registry.Decode(parsedCardData)
+		if err != nil {
+			t.Errorf("Unable to Decode %v: %v", cardFormat.Name(), err)
+			return
+		}
+		require.Equal(t, &osdp.CardCredential{Format: cardFormat.Name(), FacilityCode: 123, CardNumber: 4567}, credential)
+	}
+
+	rawCardData, err := osdp.NewRawCardData(0x00, osdp.CardFormatCorporate1000_35, 100, 12345)
+	if err != nil {
+		t.Errorf("Unable to Encode Card Data: %v", err)
+		return
+	}
+	require.Equal(t, []byte{0x00, 0x01, 0x23, 0x00, 0xC1, 0x90, 0x0C, 0x0E, 0x40}, rawCardData.ToBytes())
+
+	_, err = osdp.NewRawCardData(0x00, osdp.CardFormatH10301, 256, 1)
+	require.Equal(t, osdp.CardFieldOutOfRangeError, err)
+}
+
+func TestParseCardRead(t *testing.T) {
+	registry := osdp.NewStandardCardFormatRegistry()
+
+	rawMessage := &osdp.OSDPMessage{MessageCode: osdp.REPLY_RAW, MessageData: []byte{0x00, 0x01, 0x1A, 0x00, 0x80, 0x80, 0x00, 0x80}}
+	cardRead, err := osdp.ParseCardRead(rawMessage, registry)
+	if err != nil {
+		t.Errorf("Unable to Parse Raw Card Read: %v", err)
+		return
+	}
+	require.Equal(t, "1", cardRead.CardNumber)
+	require.Equal(t, &osdp.CardCredential{Format: "H10301", FacilityCode: 1, CardNumber: 1}, cardRead.Credential)
+	require.Nil(t, cardRead.Formatted)
+
+	formattedMessage := &osdp.OSDPMessage{MessageCode: osdp.REPLY_FMT, MessageData: []byte{0x01, 0x00, 0x05, '1', '2', '3', '4', '5'}}
+	cardRead, err = osdp.ParseCardRead(formattedMessage, registry)
+	if err != nil {
+		t.Errorf("Unable to Parse Formatted Card Read: %v", err)
+		return
+	}
+	require.Equal(t, byte(0x01), cardRead.ReaderNumber)
+	require.Equal(t, "12345", cardRead.CardNumber)
+	require.Equal(t, osdp.ReadForward, cardRead.Formatted.Direction)
+	require.Nil(t, cardRead.Raw)
+
+	_, err = osdp.ParseCardRead(&osdp.OSDPMessage{MessageCode: osdp.REPLY_FMT, MessageData: []byte{0x01, 0x00, 0x05, '1'}}, registry)
+	require.Equal(t, osdp.ReplyDataLengthError, err)
+	_, err = osdp.ParseCardRead(&osdp.OSDPMessage{MessageCode: osdp.REPLY_ACK}, registry)
+	require.Equal(t, osdp.UnexpectedReplyError, err)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	osdp "github.com/verkada/go-osdp"
+)
+
+func TestCodeNames(t *testing.T) {
+	require.Equal(t, "osdp_KEEPACTIVE", osdp.CMD_KEEPACTIVE.String())
+	require.Equal(t, "osdp_MFGERRR", osdp.REPLY_MFGERRR.String())
+	require.Equal(t, "osdp_MFG/osdp_PIVDATAR", osdp.CMD_MFG.String())
+	require.Equal(t, "osdp_FTSTAT", osdp.REPLY_FTSTAT.ReplyString())
+	require.Equal(t, "OSDPCode(0x7A)", osdp.REPLY_FTSTAT.CommandString())
+	require.Equal(t, "OSDPCode(0xFF)", osdp.OSDPCode(0xFF).String())
+
+	require.Equal(t, true, osdp.CMD_ACURXSIZE.IsCommand())
+	require.Equal(t, false, osdp.CMD_ACURXSIZE.IsReply())
+	require.Equal(t, true, osdp.REPLY_XRD.IsReply())
+}
+
+func TestValidReplies(t *testing.T) {
+	require.Equal(t, []osdp.OSDPCode{osdp.REPLY_FTSTAT}, osdp.ValidReplies(osdp.CMD_FILETRANSFER))
+	require.Equal(t, true, osdp.IsValidReply(osdp.CMD_CRAUTH, osdp.REPLY_CRAUTHR))
+	require.Equal(t, true, osdp.IsValidReply(osdp.CMD_LED, osdp.REPLY_NAK))
+	require.Equal(t, true, osdp.IsValidReply(osdp.CMD_POLL, osdp.REPLY_RAW))
+	require.Equal(t, false, osdp.IsValidReply(osdp.CMD_POLL, osdp.REPLY_PDID))
+	require.Equal(t, false, osdp.IsValidReply(osdp.REPLY_ACK, osdp.REPLY_ACK))
+}
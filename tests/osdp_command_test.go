@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	osdp "github.com/verkada/go-osdp"
+)
+
+func TestLEDCommandEncoding(t *testing.T) {
+	osdpMessage, err := osdp.NewLEDMessage(0x01, 0x01, osdp.LEDAccessGranted(0x00, 0x00), osdp.LEDSteady(0x00, 0x01, osdp.LEDColorBlue))
+	if err != nil {
+		t.Errorf("Unable to Create LED Message: %v", err)
+		return
+	}
+
+	correctData := []byte{
+		0x00, 0x00, 0x02, 0x05, 0x05, 0x02, 0x00, 0x14, 0x00, 0x01, 0x01, 0x00, 0x01, 0x01,
+		0x00, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x01, 0x00, 0x04, 0x04,
+	}
+	require.Equal(t, osdp.CMD_LED, osdpMessage.MessageCode)
+	require.Equal(t, correctData, osdpMessage.MessageData)
+}
+
+func TestLEDCommandValidation(t *testing.T) {
+	_, err := osdp.NewLEDMessage(0x01, 0x01)
+	require.Equal(t, osdp.EmptyCommandError, err)
+
+	ledControl := osdp.LEDSteady(0x00, 0x00, osdp.LEDColorRed)
+	ledControl.Permanent.OnColor = 0x08
+	_, err = osdp.NewLEDMessage(0x01, 0x01, ledControl)
+	require.Equal(t, osdp.InvalidLEDColorError, err)
+
+	_, err = osdp.LEDFlash(0x00, 0x00, osdp.LEDColorGreen, 50*time.Millisecond, 50*time.Millisecond, 3)
+	require.Equal(t, osdp.LEDTimeOutOfRangeError, err)
+	_, err = osdp.LEDFlash(0x00, 0x00, osdp.LEDColorGreen, 200*time.Millisecond, 50*time.Millisecond, 3)
+	require.Equal(t, osdp.LEDTimeOutOfRangeError, err)
+
+	ledControl, err = osdp.LEDFlash(0x00, 0x00, osdp.LEDColorGreen, 200*time.Millisecond, 100*time.Millisecond, 3)
+	require.Equal(t, nil, err)
+	require.Equal(t, osdp.LEDTemporaryState{ControlCode: osdp.LEDTemporarySet, OnTime: 2, OffTime: 1, OnColor: osdp.LEDColorGreen, Timer: 9}, ledControl.Temporary)
+
+	ledControl.Temporary.Timer = 0
+	_, err = osdp.NewLEDMessage(0x01, 0x01, ledControl)
+	require.Equal(t, osdp.LEDTimeOutOfRangeError, err)
+}
+
+func TestBuzzerCommandValidation(t *testing.T) {
+	_, err := osdp.NewBuzzerCommand(0x00, 0, 100*time.Millisecond, 1)
+	require.Equal(t, osdp.BuzzerTimeOutOfRangeError, err)
+
+	_, err = osdp.NewBuzzerCommand(0x00, 30*time.Second, 100*time.Millisecond, 1)
+	require.Equal(t, osdp.BuzzerTimeOutOfRangeError, err)
+	_, err = osdp.NewBuzzerCommand(0x00, 50*time.Millisecond, 100*time.Millisecond, 1)
+	require.Equal(t, osdp.BuzzerTimeOutOfRangeError, err)
+	_, err = osdp.NewBuzzerCommand(0x00, 200*time.Millisecond, 50*time.Millisecond, 1)
+	require.Equal(t, osdp.BuzzerTimeOutOfRangeError, err)
+
+	buzzerCommand, err := osdp.NewBuzzerCommand(0x00, 200*time.Millisecond, 100*time.Millisecond, 2)
+	if err != nil {
+		t.Errorf("Unable to Create Buzzer Command: %v", err)
+		return
+	}
+	require.Equal(t, []byte{0x00, 0x02, 0x02, 0x01, 0x02}, buzzerCommand.ToBytes())
+
+	buzzerCommand.ToneCode = 0x03
+	require.Equal(t, osdp.InvalidBuzzerToneError, buzzerCommand.Validate())
+}
+
+func TestMessengerCommandQueue(t *testing.T) {
+	messenger := osdp.NewOSDPMessenger(&MockTransceiver{}, false)
+	err := messenger.QueueCommand(0x01, osdp.LEDCommand{osdp.LEDAccessGranted(0x00, 0x00)}, osdp.BuzzerAccessGranted(0x00))
+	if err != nil {
+		t.Errorf("Unable to Queue Commands: %v", err)
+		return
+	}
+	require.Equal(t, 2, messenger.PendingCommands(0x01))
+	require.Equal(t, 0, messenger.PendingCommands(0x02))
+
+	expectedCodes := []osdp.OSDPCode{osdp.CMD_LED, osdp.CMD_BUZ, osdp.CMD_POLL}
+	for _, expectedCode := range expectedCodes {
+		osdpMessage, err := messenger.NextMessage(0x01)
+		if err != nil {
+			t.Errorf("Unable to Get Next Message: %v", err)
+			return
+		}
+		require.Equal(t, expectedCode, osdpMessage.MessageCode)
+	}
+}
+
+func TestTextCommandValidation(t *testing.T) {
+	textCommand := osdp.TextPresentCard(0x00)
+	require.Equal(t, nil, textCommand.ValidateDisplay(osdp.TextDisplaySizeFromCompliance(0x01)))
+	require.Equal(t, []byte{0x00, 0x01, 0x00, 0x01, 0x01, 0x0C, 'P', 'r', 'e', 's', 'e', 'n', 't', ' ', 'c', 'a', 'r', 'd'}, textCommand.ToBytes())
+
+	textCommand.Column = 8
+	require.Equal(t, osdp.TextTooLongError, textCommand.ValidateDisplay(osdp.TextDisplaySizeFromCompliance(0x01)))
+	textCommand.CommandCode = osdp.TextPermanentWrap
+	require.Equal(t, nil, textCommand.ValidateDisplay(osdp.TextDisplaySizeFromCompliance(0x02)))
+
+	textCommand.Text = "Enter\tPIN"
+	require.Equal(t, osdp.InvalidTextCharacterError, textCommand.Validate())
+}
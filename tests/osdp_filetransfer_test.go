@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	osdp "github.com/verkada/go-osdp"
+)
+
+func fileTransferStatus(action byte, delay uint16, status osdp.FileTransferStatus, updateMessageMax uint16) []byte {
+	msgData := make([]byte, 7)
+	msgData[0] = action
+	binary.LittleEndian.PutUint16(msgData[1:3], delay)
+	binary.LittleEndian.PutUint16(msgData[3:5], uint16(status))
+	binary.LittleEndian.PutUint16(msgData[5:7], updateMessageMax)
+	return msgData
+}
+
+func TestFileTransfer(t *testing.T) {
+	firmware := make([]byte, 300)
+	for i := range firmware {
+		firmware[i] = byte(i)
+	}
+	received := []byte{}
+	idleMessages := 0
+	dropNext := false
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		msgData := osdpPacket.GetMessageData()
+		require.Equal(t, osdp.CMD_FILETRANSFER, osdp.OSDPCode(osdpPacket.GetMessageCode()))
+		require.Equal(t, uint32(len(firmware)), binary.LittleEndian.Uint32(msgData[1:5]))
+		require.Equal(t, uint32(len(received)), binary.LittleEndian.Uint32(msgData[5:9]))
+		if dropNext {
+			dropNext = false
+			return nil
+		}
+		fragmentSize := int(binary.LittleEndian.Uint16(msgData[9:11]))
+		received = append(received, msgData[11:11+fragmentSize]...)
+		switch {
+		case fragmentSize == 0:
+			idleMessages++
+			return replyPacket(osdpPacket, osdp.REPLY_FTSTAT, fileTransferStatus(0x00, 0, osdp.FileTransferRebooting, 0))
+		case len(received) == len(firmware):
+			return replyPacket(osdpPacket, osdp.REPLY_FTSTAT, fileTransferStatus(0x00, 20, osdp.FileTransferFinishing, 0))
+		}
+		return replyPacket(osdpPacket, osdp.REPLY_FTSTAT, fileTransferStatus(0x01, 0, osdp.FileTransferProceed, 100))
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	progress := []int{}
+	fileTransfer := osdp.NewFileTransfer(0x01, osdp.FileTransferOpaque, firmware, func(transferred int, total int) {
+		progress = append(progress, transferred)
+	})
+	done, err := fileTransfer.Step(context.Background(), messenger)
+	require.Equal(t, nil, err)
+	require.Equal(t, false, done)
+	require.Equal(t, 128, fileTransfer.Offset())
+	require.Equal(t, true, fileTransfer.LastStatus().Interleave())
+
+	dropNext = true
+	_, err = fileTransfer.Step(context.Background(), messenger)
+	require.Equal(t, osdp.OSDPReceiveTimeoutError, err)
+	require.Equal(t, 128, fileTransfer.Offset())
+
+	for !done {
+		done, err = fileTransfer.Step(context.Background(), messenger)
+		if err != nil {
+			t.Errorf("Unable to Step File Transfer: %v", err)
+			return
+		}
+		if fileTransfer.LastStatus().Status == osdp.FileTransferFinishing {
+			done, err = fileTransfer.Step(context.Background(), messenger)
+			require.Equal(t, nil, err)
+			require.Equal(t, false, done)
+			require.Equal(t, 0, idleMessages)
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	require.Equal(t, firmware, received)
+	require.Equal(t, []int{128, 228, 300}, progress)
+	require.Equal(t, 1, idleMessages)
+	require.Equal(t, osdp.FileTransferRebooting, fileTransfer.LastStatus().Status)
+}
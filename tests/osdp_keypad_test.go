@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	osdp "github.com/verkada/go-osdp"
+)
+
+func TestParseKeypadData(t *testing.T) {
+	keypadData, err := osdp.ParseKeypadData([]byte{0x00, 0x04, '1', '2', 0x7F, 0x0D})
+	if err != nil {
+		t.Errorf("Unable to Parse Keypad Data: %v", err)
+		return
+	}
+	require.Equal(t, &osdp.KeypadData{ReaderNumber: 0x00, Keys: "12*#"}, keypadData)
+
+	_, err = osdp.ParseKeypadData([]byte{0x00, 0x04, '1'})
+	require.Equal(t, osdp.ReplyDataLengthError, err)
+}
+
+func TestPINSessionTerminatorAndTimeout(t *testing.T) {
+	credentials := []*osdp.PINCredential{}
+	config := osdp.PINSessionConfig{InterDigitTimeout: 50 * time.Millisecond, MaxLength: 6, TerminatorKey: osdp.KeypadHash}
+	pinSessions := osdp.NewPINSessions(config, func(pinCredential *osdp.PINCredential) {
+		credentials = append(credentials, pinCredential)
+	})
+
+	pinSessions.HandleKeypad(&osdp.KeypadData{ReaderNumber: 0x00, Keys: "12"})
+	pinSessions.HandleKeypad(&osdp.KeypadData{ReaderNumber: 0x01, Keys: "9"})
+	pinSessions.HandleKeypad(&osdp.KeypadData{ReaderNumber: 0x00, Keys: "34#"})
+	require.Equal(t, []*osdp.PINCredential{{ReaderNumber: 0x00, PIN: "1234"}}, credentials)
+
+	// Reader 1 times out between digits so only the later digits count
+	time.Sleep(80 * time.Millisecond)
+	pinSessions.HandleKeypad(&osdp.KeypadData{ReaderNumber: 0x01, Keys: "123456"})
+	require.Equal(t, 2, len(credentials))
+	require.Equal(t, "123456", credentials[1].PIN)
+}
+
+func TestPINSessionDefaults(t *testing.T) {
+	credentials := []*osdp.PINCredential{}
+	pinSessions := osdp.NewPINSessions(osdp.PINSessionConfig{MaxLength: 4}, func(pinCredential *osdp.PINCredential) {
+		credentials = append(credentials, pinCredential)
+	})
+
+	// No inter-digit timeout, one key per report, and # is not a digit without a terminator
+	for _, key := range []string{"1", "2", "#", "3", "4"} {
+		pinSessions.HandleKeypad(&osdp.KeypadData{ReaderNumber: 0x00, Keys: key})
+		pinSessions.CheckTimeouts()
+	}
+	require.Equal(t, []*osdp.PINCredential{{ReaderNumber: 0x00, PIN: "1234"}}, credentials)
+}
+
+func TestPINSessionCardPairing(t *testing.T) {
+	credentials := []*osdp.PINCredential{}
+	config := osdp.PINSessionConfig{InterDigitTimeout: time.Second, MaxLength: 4, CardPINWindow: 50 * time.Millisecond}
+	pinSessions := osdp.NewPINSessions(config, func(pinCredential *osdp.PINCredential) {
+		credentials = append(credentials, pinCredential)
+	})
+	cardRead := &osdp.CardRead{ReaderNumber: 0x00, CardNumber: "40042"}
+
+	pinSessions.HandleCardRead(cardRead)
+	pinSessions.HandleKeypad(&osdp.KeypadData{ReaderNumber: 0x00, Keys: "1234"})
+	require.Equal(t, []*osdp.PINCredential{{ReaderNumber: 0x00, PIN: "1234", Card: cardRead}}, credentials)
+
+	// PIN first, card after the window has closed
+	pinSessions.HandleKeypad(&osdp.KeypadData{ReaderNumber: 0x00, Keys: "5678"})
+	time.Sleep(80 * time.Millisecond)
+	pinSessions.CheckTimeouts()
+	pinSessions.HandleCardRead(cardRead)
+	require.Equal(t, 1, len(credentials))
+}
+
+func TestPromptCollectsKeypadEntry(t *testing.T) {
+	keypadReplies := [][]byte{
+		{0x00, 0x02, '1', '2'},
+		{0x01, 0x01, '9'},
+		{0x00, 0x02, '3', 0x0D},
+	}
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		if osdp.OSDPCode(osdpPacket.GetMessageCode()) == osdp.CMD_POLL && len(keypadReplies) > 0 {
+			keypadReply := keypadReplies[0]
+			keypadReplies = keypadReplies[1:]
+			return replyPacket(osdpPacket, osdp.REPLY_KEYPAD, keypadReply)
+		}
+		return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	promptCommand := &osdp.PromptCommand{ReaderNumber: 0x00, Timeout: 10, Text: "Enter PIN"}
+	keypadData, err := messenger.Prompt(context.Background(), 0x01, promptCommand, 6, osdp.KeypadHash)
+	if err != nil {
+		t.Errorf("Unable to Prompt: %v", err)
+		return
+	}
+	require.Equal(t, &osdp.KeypadData{ReaderNumber: 0x00, Keys: "123"}, keypadData)
+	require.Equal(t, append([]byte{0x00, 0x0A, 0x09}, "Enter PIN"...), transceiver.transmitted[0].GetMessageData())
+
+	_, err = messenger.Prompt(context.Background(), 0x01, &osdp.PromptCommand{Text: "Enter\tPIN"}, 6, osdp.KeypadHash)
+	require.Equal(t, osdp.InvalidTextCharacterError, err)
+}
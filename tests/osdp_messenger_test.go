@@ -0,0 +1,561 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	osdp "github.com/verkada/go-osdp"
+)
+
+func TestPulseOutput(t *testing.T) {
+	outputActive := false
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		switch osdp.OSDPCode(osdpPacket.GetMessageCode()) {
+		case osdp.CMD_OUT:
+			outputActive = true
+			return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+		case osdp.CMD_OSTAT:
+			if outputActive {
+				return replyPacket(osdpPacket, osdp.REPLY_OSTATR, []byte{0x00, 0x01})
+			}
+			return replyPacket(osdpPacket, osdp.REPLY_OSTATR, []byte{0x00, 0x00})
+		}
+		return nil
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	err := messenger.PulseOutput(context.Background(), 0x01, 0x01, 1*time.Second)
+	require.Equal(t, nil, err)
+	require.Equal(t, 2, len(transceiver.transmitted))
+	require.Equal(t, []byte{0x01, 0x05, 0x0A, 0x00}, transceiver.transmitted[0].GetMessageData())
+	require.Equal(t, byte(0x00), transceiver.transmitted[0].GetSequenceNumber())
+	require.Equal(t, byte(0x01), transceiver.announcements[0].GetSequenceNumber())
+	require.Equal(t, byte(0x02), transceiver.transmitted[1].GetSequenceNumber())
+
+	outputActive = false
+	err = messenger.PulseOutput(context.Background(), 0x01, 0x00, 1*time.Second)
+	require.Equal(t, osdp.OutputStateMismatchError, err)
+
+	// A PD answering osdp_OUT with its output status is not polled for it again
+	transceiver = NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_OSTATR, []byte{0x00, 0x01})
+	})
+	messenger = osdp.NewOSDPMessenger(transceiver, false)
+	err = messenger.PulseOutput(context.Background(), 0x01, 0x01, 1*time.Second)
+	require.Equal(t, nil, err)
+	require.Equal(t, 1, len(transceiver.transmitted))
+	err = messenger.PulseOutput(context.Background(), 0x01, 0x00, 1*time.Second)
+	require.Equal(t, osdp.OutputStateMismatchError, err)
+}
+
+func TestChangeCommunicationSettings(t *testing.T) {
+	pdAddress := byte(0x01)
+	pdBaudRate := uint32(9600)
+	transceiver := &BaudRateTransceiver{baudRate: 9600}
+	transceiver.ScriptedTransceiver = NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		if osdpPacket.GetPeripheralAddress() != pdAddress || transceiver.baudRate != pdBaudRate {
+			return nil
+		}
+		if osdp.OSDPCode(osdpPacket.GetMessageCode()) == osdp.CMD_COMSET {
+			settings, err := osdp.ParseCommunicationSettings(osdpPacket.GetMessageData())
+			if err != nil {
+				return nil
+			}
+			reply := replyPacket(osdpPacket, osdp.REPLY_COM, osdpPacket.GetMessageData())
+			pdAddress, pdBaudRate = settings.PeripheralAddress, settings.BaudRate
+			return reply
+		}
+		return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	settings, err := messenger.ChangeCommunicationSettings(context.Background(), 0x01, &osdp.CommunicationSettings{PeripheralAddress: 0x02, BaudRate: 115200})
+	require.Equal(t, nil, err)
+	require.Equal(t, &osdp.CommunicationSettings{PeripheralAddress: 0x02, BaudRate: 115200}, settings)
+	require.Equal(t, uint32(115200), transceiver.baudRate)
+	require.Equal(t, []byte{0x02, 0x00, 0xC2, 0x01, 0x00}, transceiver.transmitted[0].GetMessageData())
+
+	// The PD switches address but never comes back at the new one
+	transceiver.respond = func(osdpPacket *osdp.OSDPPacket) []byte {
+		if osdp.OSDPCode(osdpPacket.GetMessageCode()) == osdp.CMD_COMSET {
+			return replyPacket(osdpPacket, osdp.REPLY_COM, osdpPacket.GetMessageData())
+		}
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = messenger.ChangeCommunicationSettings(ctx, 0x02, &osdp.CommunicationSettings{PeripheralAddress: 0x03, BaudRate: 9600})
+	require.Equal(t, osdp.ComSetNotConfirmedError, err)
+	require.Equal(t, uint32(115200), transceiver.baudRate)
+
+	// The transceiver cannot be switched back to its old speed
+	transceiver.failBaudRate = 115200
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = messenger.ChangeCommunicationSettings(ctx, 0x02, &osdp.CommunicationSettings{PeripheralAddress: 0x02, BaudRate: 9600})
+	var comSetRollbackError *osdp.ComSetRollbackError
+	require.True(t, errors.As(err, &comSetRollbackError))
+	require.True(t, errors.Is(err, osdp.ComSetNotConfirmedError))
+	require.Equal(t, uint32(9600), transceiver.baudRate)
+}
+
+func TestCommunicationSettingsValidation(t *testing.T) {
+	err := (&osdp.CommunicationSettings{PeripheralAddress: 0x01, BaudRate: 12345}).Validate()
+	require.Equal(t, osdp.InvalidBaudRateError, err)
+	err = (&osdp.CommunicationSettings{PeripheralAddress: 0x7F, BaudRate: 9600}).Validate()
+	require.Equal(t, osdp.AddressOutOfRangeError, err)
+}
+
+func TestIdentify(t *testing.T) {
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		pdid := []byte{0x5C, 0x26, 0x23, 0x19, 0x02, 0x78, 0x56, 0x34, 0x12, 0x01, 0x04, 0x1F}
+		return replyPacket(osdpPacket, osdp.REPLY_PDID, pdid)
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	identification, err := messenger.Identify(context.Background(), 0x01)
+	if err != nil {
+		t.Errorf("Unable to Identify PD: %v", err)
+		return
+	}
+	require.Equal(t, osdp.CMD_ID, osdp.OSDPCode(transceiver.transmitted[0].GetMessageCode()))
+	require.Equal(t, "5C-26-23", identification.VendorCode.String())
+	require.Equal(t, byte(0x19), identification.ModelNumber)
+	require.Equal(t, uint32(0x12345678), identification.SerialNumber)
+	require.Equal(t, "1.4.31", identification.FirmwareVersion())
+
+	_, err = osdp.ParsePeripheralIdentification([]byte{0x5C, 0x26, 0x23})
+	require.Equal(t, osdp.ReplyDataLengthError, err)
+}
+
+func TestQueryCapabilities(t *testing.T) {
+	pdcap := []byte{
+		0x01, 0x01, 0x04, 0x02, 0x01, 0x02, 0x04, 0x01, 0x01, 0x06, 0x02, 0x01,
+		0x08, 0x01, 0x00, 0x09, 0x01, 0x01, 0x0A, 0x00, 0x04, 0x0B, 0x00, 0x10,
+		0x0C, 0x03, 0x00, 0x0D, 0x00, 0x01,
+	}
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_PDCAP, pdcap)
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+	require.Nil(t, messenger.Capabilities(0x01))
+
+	capabilities, err := messenger.QueryCapabilities(context.Background(), 0x01)
+	if err != nil {
+		t.Errorf("Unable to Query Capabilities: %v", err)
+		return
+	}
+	require.Equal(t, capabilities, messenger.Capabilities(0x01))
+	require.Equal(t, 4, capabilities.Inputs())
+	require.Equal(t, 2, capabilities.Outputs())
+	require.Equal(t, 1, capabilities.Readers())
+	require.Equal(t, osdp.TextDisplaySize{Rows: 2, Columns: 16}, capabilities.TextDisplaySize())
+	require.Equal(t, true, capabilities.SupportsCRC())
+	require.Equal(t, true, capabilities.SupportsSecureChannel())
+	require.Equal(t, true, capabilities.SupportsTransparentReader())
+	require.Equal(t, true, capabilities.SupportsExtendedPacketMode())
+	require.Equal(t, false, capabilities.SupportsBiometrics())
+	require.Equal(t, 1024, capabilities.ReceiveBufferSize())
+	require.Equal(t, 4096, capabilities.LargestCombinedMessageSize())
+
+	// Text that does not fit the reported 2x16 display is rejected before it is sent
+	textCommand := &osdp.TextCommand{ReaderNumber: 0x00, CommandCode: osdp.TextPermanentNoWrap, Row: 3, Column: 1, Text: "Enter PIN"}
+	require.Equal(t, osdp.InvalidTextPositionError, messenger.QueueCommand(0x01, textCommand))
+	require.Equal(t, nil, messenger.QueueCommand(0x01, osdp.TextEnterPIN(0x00)))
+}
+
+func TestPhysicalStatus(t *testing.T) {
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		switch osdp.OSDPCode(osdpPacket.GetMessageCode()) {
+		case osdp.CMD_LSTAT:
+			return replyPacket(osdpPacket, osdp.REPLY_LSTATR, []byte{0x01, 0x00})
+		case osdp.CMD_ISTAT:
+			return replyPacket(osdpPacket, osdp.REPLY_ISTATR, []byte{0x00, 0x01, 0x00})
+		case osdp.CMD_OSTAT:
+			return replyPacket(osdpPacket, osdp.REPLY_OSTATR, []byte{0x01})
+		case osdp.CMD_RSTAT:
+			return replyPacket(osdpPacket, osdp.REPLY_RSTATR, []byte{0x02})
+		}
+		return nil
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	physicalStatus, err := messenger.PhysicalStatus(context.Background(), 0x01)
+	if err != nil {
+		t.Errorf("Unable to Get Physical Status: %v", err)
+		return
+	}
+	correctStatus := &osdp.PhysicalStatus{
+		Local:   &osdp.LocalStatus{Tamper: true, PowerFailure: false},
+		Inputs:  []bool{false, true, false},
+		Outputs: []bool{true},
+		Readers: []osdp.ReaderTamperStatus{osdp.ReaderTamper},
+	}
+	require.Equal(t, correctStatus, physicalStatus)
+
+	_, err = osdp.ParseLocalStatus([]byte{0x00})
+	require.Equal(t, osdp.ReplyDataLengthError, err)
+}
+
+func TestNakAsError(t *testing.T) {
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_NAK, []byte{osdp.ERR_BAD_CMD, 0x6B})
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+	osdpMessage, err := osdp.NewOSDPMessage(osdp.CMD_TEXT, 0x01, 0x00, []byte{})
+	if err != nil {
+		t.Errorf("Unable to Create OSDP Message: %v", err)
+		return
+	}
+
+	reply, err := messenger.SendAndReceive(osdpMessage, time.Second, time.Second)
+	require.Equal(t, nil, err)
+	require.Equal(t, osdp.REPLY_NAK, reply.MessageCode)
+	require.True(t, errors.Is(reply.NakError(), osdp.NakBadCommandError))
+
+	messenger.SetNakAsError(true)
+	_, err = messenger.SendAndReceive(osdpMessage, time.Second, time.Second)
+	require.True(t, errors.Is(err, osdp.NakBadCommandError))
+	require.False(t, errors.Is(err, osdp.NakBadSequenceError))
+	var nakError *osdp.NakError
+	require.True(t, errors.As(err, &nakError))
+	require.Equal(t, []byte{0x6B}, nakError.Data)
+
+	// Typed helpers surface the NAK whatever the setting
+	messenger.SetNakAsError(false)
+	_, err = messenger.Identify(context.Background(), 0x01)
+	require.True(t, errors.Is(err, osdp.NakBadCommandError))
+}
+
+func TestBusyReplyIsRepolled(t *testing.T) {
+	busyReplies := 2
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		if busyReplies > 0 {
+			busyReplies--
+			return replyPacket(osdpPacket, osdp.REPLY_BUSY, []byte{})
+		}
+		return replyPacket(osdpPacket, osdp.REPLY_LSTATR, []byte{0x00, 0x00})
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	localStatus, err := messenger.LocalStatus(context.Background(), 0x01)
+	require.Equal(t, nil, err)
+	require.Equal(t, &osdp.LocalStatus{}, localStatus)
+	require.Equal(t, 3, len(transceiver.transmitted))
+	require.Equal(t, osdp.CMD_POLL, osdp.OSDPCode(transceiver.transmitted[2].GetMessageCode()))
+	require.Equal(t, transceiver.transmitted[0].GetSequenceNumber(), transceiver.transmitted[2].GetSequenceNumber())
+	require.Equal(t, uint64(2), messenger.Metrics().BusyReplies)
+
+	// A PD that stays busy is reported as a timeout
+	busyReplies = 100
+	messenger.SetBusyTimeout(120 * time.Millisecond)
+	_, err = messenger.LocalStatus(context.Background(), 0x01)
+	require.Equal(t, osdp.OSDPReceiveTimeoutError, err)
+	require.Equal(t, uint64(1), messenger.Metrics().BusyTimeouts)
+}
+
+func TestACUReceiveSizeSentWhenOnline(t *testing.T) {
+	online := true
+	supported := true
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		if !online {
+			return nil
+		}
+		if osdp.OSDPCode(osdpPacket.GetMessageCode()) == osdp.CMD_ACURXSIZE && !supported {
+			return replyPacket(osdpPacket, osdp.REPLY_NAK, []byte{osdp.ERR_BAD_CMD})
+		}
+		return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+	})
+	transceiver.scriptReceiveSize = true
+	lastCodes := func(count int) []osdp.OSDPCode {
+		codes := []osdp.OSDPCode{}
+		for _, osdpPacket := range transceiver.transmitted[len(transceiver.transmitted)-count:] {
+			codes = append(codes, osdp.OSDPCode(osdpPacket.GetMessageCode()))
+		}
+		return codes
+	}
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+	require.Equal(t, osdp.InvalidReceiveSizeError, messenger.SetACUReceiveSize(16))
+
+	// The poll loop sends the default receive size ahead of the queue
+	require.Equal(t, nil, messenger.QueueCommand(0x01, osdp.BuzzerOff(0x00)))
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, 5*time.Second))
+	require.Equal(t, []byte{0x88, 0x13}, transceiver.transmitted[0].GetMessageData())
+	require.Equal(t, true, messenger.Online(0x01))
+	osdpMessage, err := messenger.NextMessage(0x01)
+	require.Equal(t, nil, err)
+	require.Equal(t, osdp.CMD_ACURXSIZE, osdpMessage.MessageCode)
+	require.Equal(t, []byte{0x00, 0x04}, osdpMessage.MessageData)
+	require.Equal(t, 1, messenger.PendingCommands(0x01))
+	osdpMessage, err = messenger.NextMessage(0x01)
+	require.Equal(t, nil, err)
+	require.Equal(t, osdp.CMD_BUZ, osdpMessage.MessageCode)
+
+	// Typed helpers send it too, once per time the PD comes back online however often it flaps
+	require.Equal(t, nil, messenger.SetACUReceiveSize(512))
+	for i := 0; i < 2; i++ {
+		online = false
+		require.Equal(t, osdp.OSDPReceiveTimeoutError, messenger.KeepActive(context.Background(), 0x01, time.Second))
+		require.Equal(t, false, messenger.Online(0x01))
+		online = true
+		require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	}
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	require.Equal(t, []osdp.OSDPCode{osdp.CMD_ACURXSIZE, osdp.CMD_KEEPACTIVE}, lastCodes(2))
+	require.Equal(t, []byte{0x00, 0x02}, transceiver.transmitted[len(transceiver.transmitted)-2].GetMessageData())
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	require.Equal(t, []osdp.OSDPCode{osdp.CMD_KEEPACTIVE, osdp.CMD_KEEPACTIVE}, lastCodes(2))
+
+	// A PD that does not know the command is not sent it again
+	supported = false
+	messenger = osdp.NewOSDPMessenger(transceiver, false)
+	messenger.SetNakAsError(true)
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	require.Equal(t, []osdp.OSDPCode{osdp.CMD_ACURXSIZE, osdp.CMD_KEEPACTIVE}, lastCodes(2))
+	online = false
+	require.Equal(t, osdp.OSDPReceiveTimeoutError, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	online = true
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	require.Equal(t, []osdp.OSDPCode{osdp.CMD_KEEPACTIVE, osdp.CMD_KEEPACTIVE}, lastCodes(2))
+}
+
+func TestRunDiagnostic(t *testing.T) {
+	failTest := false
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		if failTest {
+			return replyPacket(osdpPacket, osdp.REPLY_NAK, []byte{osdp.ERR_UNKNOWN})
+		}
+		return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	manufacturerReply, err := messenger.RunDiagnostic(context.Background(), 0x01, &osdp.DiagnosticCommand{Test: osdp.DiagnosticSelfTest})
+	require.Equal(t, nil, err)
+	require.Nil(t, manufacturerReply)
+	require.Equal(t, osdp.CMD_DIAG, osdp.OSDPCode(transceiver.transmitted[0].GetMessageCode()))
+	require.Equal(t, []byte{0x00}, transceiver.transmitted[0].GetMessageData())
+
+	failTest = true
+	_, err = messenger.RunDiagnostic(context.Background(), 0x01, &osdp.DiagnosticCommand{Test: osdp.DiagnosticSelfTest})
+	require.True(t, errors.Is(err, osdp.NakUnknownError))
+
+	transceiver.respond = func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_MFGREP, []byte{0x0A, 0x00, 0x17, 0x10, 0x00, 0x2C})
+	}
+	manufacturerReply, err = messenger.RunDiagnostic(context.Background(), 0x01, &osdp.DiagnosticCommand{Test: 0x10})
+	require.Equal(t, nil, err)
+	require.Equal(t, &osdp.ManufacturerReply{VendorCode: osdp.VendorCode{0x0A, 0x00, 0x17}, CommandID: 0x10, Payload: []byte{0x00, 0x2C}}, manufacturerReply)
+	require.Equal(t, true, osdp.IsValidReply(osdp.CMD_DIAG, osdp.REPLY_MFGREP))
+}
+
+func TestSequenceNumberManagement(t *testing.T) {
+	var reply func(osdpPacket *osdp.OSDPPacket) []byte
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		return reply(osdpPacket)
+	})
+	ack := func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+	}
+	transceiver.scriptReceiveSize = true
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	reply = ack
+	for i := 0; i < 5; i++ {
+		osdpMessage, err := messenger.NextMessage(0x01)
+		require.Equal(t, nil, err)
+		_, err = messenger.SendAndReceive(osdpMessage, time.Second, time.Second)
+		require.Equal(t, nil, err)
+	}
+	sequenceNumbers := []byte{}
+	for _, osdpPacket := range transceiver.transmitted {
+		sequenceNumbers = append(sequenceNumbers, osdpPacket.GetSequenceNumber())
+	}
+	require.Equal(t, []byte{0, 1, 2, 3, 1}, sequenceNumbers)
+
+	// A PD that stopped answering may have restarted, so it is brought back at 0
+	reply = func(osdpPacket *osdp.OSDPPacket) []byte { return nil }
+	osdpMessage, err := messenger.NextMessage(0x01)
+	require.Equal(t, nil, err)
+	_, err = messenger.SendAndReceive(osdpMessage, time.Second, 10*time.Millisecond)
+	require.Equal(t, osdp.OSDPReceiveTimeoutError, err)
+	require.Equal(t, false, messenger.Online(0x01))
+	reply = ack
+	_, err = messenger.SendAndReceive(osdpMessage, time.Second, time.Second)
+	require.Equal(t, nil, err)
+	require.Equal(t, byte(2), transceiver.transmitted[5].GetSequenceNumber())
+	require.Equal(t, byte(0), transceiver.transmitted[6].GetSequenceNumber())
+
+	// Replies must echo the number
+	reply = func(osdpPacket *osdp.OSDPPacket) []byte {
+		stale, _ := osdp.NewPacket(osdp.REPLY_ACK, 0x81, []byte{}, osdpPacket.GetSequenceNumber()%3+1, true)
+		return stale.ToBytes()
+	}
+	_, err = messenger.Identify(context.Background(), 0x01)
+	require.Equal(t, osdp.SequenceMismatchError, err)
+
+	// A sequence NAK restarts the sequence at 0
+	reply = func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_NAK, []byte{osdp.ERR_BAD_SEQ})
+	}
+	_, err = messenger.Identify(context.Background(), 0x01)
+	require.True(t, errors.Is(err, osdp.NakBadSequenceError))
+	reply = ack
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	require.Equal(t, byte(0), transceiver.transmitted[len(transceiver.transmitted)-1].GetSequenceNumber())
+}
+
+func TestLateReplyIsDiscarded(t *testing.T) {
+	var late []byte
+	var reply func(osdpPacket *osdp.OSDPPacket) []byte
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		return reply(osdpPacket)
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+	messenger.SetReplyTimeout(20 * time.Millisecond)
+	unanswered := func(osdpPacket *osdp.OSDPPacket) []byte {
+		late = replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+		return nil
+	}
+
+	ack := func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+	}
+	reply = ack
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+
+	// The late reply turns up while the next message is waiting for its own
+	reply = unanswered
+	require.Equal(t, osdp.OSDPReceiveTimeoutError, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	reply = func(osdpPacket *osdp.OSDPPacket) []byte {
+		return append(late, ack(osdpPacket)...)
+	}
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+
+	// The late reply is already waiting when the next message is sent
+	reply = unanswered
+	require.Equal(t, osdp.OSDPReceiveTimeoutError, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	transceiver.pending = append(transceiver.pending, late...)
+	reply = ack
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	require.Equal(t, 0, len(transceiver.pending))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	failures := 0
+	crcNak := false
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		if failures > 0 {
+			failures--
+			if crcNak {
+				return replyPacket(osdpPacket, osdp.REPLY_NAK, []byte{osdp.ERR_BAD_CRC})
+			}
+			return nil
+		}
+		return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+	messenger.SetRetryPolicy(2, 10*time.Millisecond)
+
+	failures = 2
+	osdpMessage, err := osdp.NewOSDPMessage(osdp.CMD_POLL, 0x01, 0, []byte{})
+	require.Equal(t, nil, err)
+	reply, err := messenger.SendAndReceive(osdpMessage, time.Second, 20*time.Millisecond)
+	require.Equal(t, nil, err)
+	require.Equal(t, osdp.REPLY_ACK, reply.MessageCode)
+	require.Equal(t, uint32(2), osdpMessage.Retries)
+	require.Equal(t, 3, len(transceiver.transmitted))
+	for _, osdpPacket := range transceiver.transmitted {
+		require.Equal(t, transceiver.transmitted[0].ToBytes(), osdpPacket.ToBytes())
+	}
+	require.Equal(t, uint64(2), messenger.Metrics().Retransmissions)
+
+	crcNak = true
+	failures = 1
+	reply, err = messenger.SendAndReceive(osdpMessage, time.Second, 20*time.Millisecond)
+	require.Equal(t, nil, err)
+	require.Equal(t, osdp.REPLY_ACK, reply.MessageCode)
+	require.Equal(t, uint32(1), osdpMessage.Retries)
+
+	// The PD is offline once the retries run out
+	crcNak = false
+	failures = 3
+	_, err = messenger.LocalStatus(context.Background(), 0x01)
+	var retryError *osdp.RetryError
+	require.True(t, errors.As(err, &retryError))
+	require.Equal(t, uint32(2), retryError.Retries)
+	require.True(t, errors.Is(err, osdp.OSDPReceiveTimeoutError))
+	require.Equal(t, false, messenger.Online(0x01))
+
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	require.Equal(t, byte(0), transceiver.transmitted[len(transceiver.transmitted)-1].GetSequenceNumber())
+}
+
+func TestRetryPolicyWithDeadline(t *testing.T) {
+	online := true
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		if !online {
+			return nil
+		}
+		return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+	messenger.SetRetryPolicy(3, 10*time.Millisecond)
+	_, err := messenger.SendCommand(context.Background(), 0x01, &osdp.KeepActiveCommand{})
+	require.Equal(t, nil, err)
+	require.Equal(t, true, messenger.Online(0x01))
+
+	// Every attempt fits within the deadline
+	online = false
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = messenger.SendCommand(ctx, 0x01, &osdp.KeepActiveCommand{})
+	var retryError *osdp.RetryError
+	require.True(t, errors.As(err, &retryError))
+	require.Equal(t, uint32(3), retryError.Retries)
+	require.Equal(t, 5, len(transceiver.transmitted))
+	require.Equal(t, false, messenger.Online(0x01))
+
+	// A deadline too short to retry ends the exchange without taking the PD offline
+	online = true
+	_, err = messenger.SendCommand(context.Background(), 0x01, &osdp.KeepActiveCommand{})
+	require.Equal(t, nil, err)
+	online = false
+	messenger.SetRetryPolicy(3, 50*time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = messenger.SendCommand(ctx, 0x01, &osdp.KeepActiveCommand{})
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.Equal(t, true, messenger.Online(0x01))
+}
+
+func TestCancelledExchangeKeepsPeripheralOnline(t *testing.T) {
+	var cancel context.CancelFunc
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		if cancel != nil {
+			cancel()
+			return nil
+		}
+		return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+	})
+	transceiver.scriptReceiveSize = true
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+	require.Equal(t, nil, messenger.SetACUReceiveSize(0))
+	messenger.SetRetryPolicy(3, 10*time.Millisecond)
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+
+	// The caller gives up while the reply is in flight
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	cancel = cancelFunc
+	require.Equal(t, context.Canceled, messenger.KeepActive(ctx, 0x01, time.Second))
+	require.Equal(t, true, messenger.Online(0x01))
+	require.Equal(t, 2, len(transceiver.transmitted))
+
+	cancel = nil
+	require.Equal(t, nil, messenger.KeepActive(context.Background(), 0x01, time.Second))
+	require.Equal(t, byte(2), transceiver.transmitted[2].GetSequenceNumber())
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	osdp "github.com/verkada/go-osdp"
+)
+
+var testVendorCode = osdp.VendorCode{0x0A, 0x00, 0x17}
+
+type volumeCodec struct{}
+
+func (codec volumeCodec) EncodeCommand(value interface{}) ([]byte, error) {
+	return []byte{value.(byte)}, nil
+}
+
+func (codec volumeCodec) DecodeReply(payload []byte) (interface{}, error) {
+	return binary.LittleEndian.Uint16(payload), nil
+}
+
+func TestManufacturerRegistry(t *testing.T) {
+	registry := osdp.NewManufacturerRegistry()
+	require.Equal(t, nil, registry.Register(testVendorCode, 0x01, volumeCodec{}))
+	require.Equal(t, osdp.MfgCommandRegisteredError, registry.Register(testVendorCode, 0x01, volumeCodec{}))
+
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		msgData := osdpPacket.GetMessageData()
+		return replyPacket(osdpPacket, osdp.REPLY_MFGREP, append(msgData[:4:4], msgData[4], 0x01))
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	value, err := messenger.SendManufacturerCommand(context.Background(), 0x01, registry, testVendorCode, 0x01, byte(0x20))
+	if err != nil {
+		t.Errorf("Unable to Send Manufacturer Command: %v", err)
+		return
+	}
+	require.Equal(t, []byte{0x0A, 0x00, 0x17, 0x01, 0x20}, transceiver.transmitted[0].GetMessageData())
+	require.Equal(t, uint16(0x0120), value)
+
+	_, err = messenger.SendManufacturerCommand(context.Background(), 0x01, registry, testVendorCode, 0x02, byte(0x20))
+	require.Equal(t, osdp.UnknownMfgCommandError, err)
+
+	transceiver.respond = func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_MFGSTATR, []byte{0x0A, 0x00, 0x17, 0x03})
+	}
+	value, err = messenger.SendManufacturerCommand(context.Background(), 0x01, registry, testVendorCode, 0x01, byte(0x20))
+	require.Equal(t, nil, err)
+	require.Equal(t, &osdp.ManufacturerStatus{VendorCode: testVendorCode, Data: []byte{0x03}}, value)
+
+	transceiver.respond = func(osdpPacket *osdp.OSDPPacket) []byte {
+		return replyPacket(osdpPacket, osdp.REPLY_MFGERRR, []byte{0x0A, 0x00, 0x17, 0xE1})
+	}
+	_, err = messenger.SendManufacturerCommand(context.Background(), 0x01, registry, testVendorCode, 0x01, byte(0x20))
+	var manufacturerError *osdp.ManufacturerError
+	require.True(t, errors.As(err, &manufacturerError))
+	require.Equal(t, []byte{0xE1}, manufacturerError.Data)
+}
@@ -82,8 +82,12 @@ func TestPacketCreationPOLL(t *testing.T) {
 }
 
 func TestPacketCreationCardScan(t *testing.T) {
-	card := []byte("00000000010011100011010101")
-	osdpPacket, err := osdp.NewPacket(osdp.REPLY_RAW, 0x00, card, 0x00, true)
+	rawCardData, err := osdp.NewRawCardData(0x00, osdp.CardFormatH10301, 0, 40042)
+	if err != nil {
+		t.Errorf("Unable to Encode Card Data: %v", err)
+		return
+	}
+	osdpPacket, err := osdp.NewPacket(osdp.REPLY_RAW, 0x00, rawCardData.ToBytes(), 0x00, true)
 	if err != nil {
 		t.Log(err)
 		t.Errorf("Unable to Create OSDP Packet")
@@ -91,10 +95,8 @@ func TestPacketCreationCardScan(t *testing.T) {
 	}
 
 	correctMessage := []byte{
-		0x53, 0x00, 0x22, 0x00, 0x04, 0x50, 0x30, 0x30, 0x30,
-		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x31, 0x30, 0x30,
-		0x31, 0x31, 0x31, 0x30, 0x30, 0x30, 0x31, 0x31, 0x30,
-		0x31, 0x30, 0x31, 0x30, 0x31, 0xFE, 0x40,
+		0x53, 0x00, 0x10, 0x00, 0x04, 0x50, 0x00, 0x01, 0x1A,
+		0x00, 0x00, 0x4E, 0x35, 0x40, 0x4C, 0x0C,
 	}
 	osdpPacketBytes := osdpPacket.ToBytes()
 	require.Equal(t, correctMessage, osdpPacketBytes)
@@ -119,24 +121,26 @@ func TestPacketDecodeACK(t *testing.T) {
 
 func TestPacketDecodeCardScan(t *testing.T) {
 	msgToDecode := []byte{
-		0x53, 0x00, 0x22, 0x00, 0x04, 0x50, 0x30, 0x30, 0x30,
-		0x30, 0x30, 0x30, 0x30, 0x30, 0x30, 0x31, 0x30, 0x30,
-		0x31, 0x31, 0x31, 0x30, 0x30, 0x30, 0x31, 0x31, 0x30,
-		0x31, 0x30, 0x31, 0x30, 0x31, 0xFE, 0x40,
+		0x53, 0x00, 0x10, 0x00, 0x04, 0x50, 0x00, 0x01, 0x1A,
+		0x00, 0x00, 0x4E, 0x35, 0x40, 0x4C, 0x0C,
 	}
 	decodedPacket, err := osdp.NewPacketFromBytes(msgToDecode)
 	if err != nil {
 		t.Errorf("Unable to Decode OSDP Packet: %v", err.Error())
 		return
 	}
-	card := []byte("00000000010011100011010101")
-	correctPacket, err := osdp.NewPacket(osdp.REPLY_RAW, 0x00, card, 0x00, true)
+	rawCardData, err := osdp.ParseRawCardData(decodedPacket.GetMessageData())
 	if err != nil {
-		t.Errorf("Unable to Create OSDP Packet: %v", err)
+		t.Errorf("Unable to Parse Card Data: %v", err)
+		return
+	}
+	credential, err := osdp.NewStandardCardFormatRegistry().Decode(rawCardData)
+	if err != nil {
+		t.Errorf("Unable to Decode Card Data: %v", err)
 		return
 	}
 
-	require.Equal(t, correctPacket, decodedPacket)
+	require.Equal(t, &osdp.CardCredential{Format: "H10301", FacilityCode: 0, CardNumber: 40042}, credential)
 }
 
 func TestMessageReceive(t *testing.T) {
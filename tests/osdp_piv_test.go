@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	osdp "github.com/verkada/go-osdp"
+)
+
+func newCardAuthKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unable to Generate Card Key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "PIV Card Authentication"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	if err != nil {
+		t.Fatalf("Unable to Create Card Certificate: %v", err)
+	}
+	return privateKey, certificate
+}
+
+// newPIVCard answers PIV commands like a reader with a card presented, acknowledging each command
+// and returning the reply fragments to the following polls
+func newPIVCard(t *testing.T, certificate []byte, signingKey *ecdsa.PrivateKey) *ScriptedTransceiver {
+	var pending []*osdp.MultiPartFragment
+	var pendingCode osdp.OSDPCode
+	crauth := &osdp.MultiPartAssembler{}
+	return NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		msgData := osdpPacket.GetMessageData()
+		switch osdp.OSDPCode(osdpPacket.GetMessageCode()) {
+		case osdp.CMD_PIVDATA:
+			require.Equal(t, []byte{0x5F, 0xC1, 0x01, 0x70, 0x00}, msgData)
+			pending, _ = osdp.SplitMultiPart(certificate, 100)
+			pendingCode = osdp.REPLY_PIVDATAR
+			return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+		case osdp.CMD_CRAUTH:
+			fragment, err := osdp.ParseMultiPartFragment(msgData)
+			require.Equal(t, nil, err)
+			complete, err := crauth.Add(fragment)
+			require.Equal(t, nil, err)
+			if complete {
+				payload := crauth.Data()
+				require.Equal(t, []byte{byte(osdp.PIVAlgorithmECCP256), osdp.PIVKeyCardAuthentication}, payload[:2])
+				signature, err := signingKey.Sign(rand.Reader, payload[2:], nil)
+				require.Equal(t, nil, err)
+				pending, _ = osdp.SplitMultiPart(signature, 40)
+				pendingCode = osdp.REPLY_CRAUTHR
+			}
+			return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+		case osdp.CMD_POLL:
+			if len(pending) == 0 {
+				return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+			}
+			fragment := pending[0]
+			pending = pending[1:]
+			return replyPacket(osdpPacket, pendingCode, fragment.ToBytes())
+		}
+		return nil
+	})
+}
+
+func TestAuthenticateCardAuthKey(t *testing.T) {
+	privateKey, certificate := newCardAuthKey(t)
+	transceiver := newPIVCard(t, certificate, privateKey)
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	cardCertificate, err := messenger.AuthenticateCardAuthKey(context.Background(), 0x01)
+	if err != nil {
+		t.Errorf("Unable to Authenticate Card: %v", err)
+		return
+	}
+	require.Equal(t, "PIV Card Authentication", cardCertificate.Subject.CommonName)
+
+	clonedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Errorf("Unable to Generate Card Key: %v", err)
+		return
+	}
+	transceiver = newPIVCard(t, certificate, clonedKey)
+	messenger = osdp.NewOSDPMessenger(transceiver, false)
+	_, err = messenger.AuthenticateCardAuthKey(context.Background(), 0x01)
+	require.Equal(t, osdp.CardAuthenticationError, err)
+}
+
+func TestPIVAuthenticationLength(t *testing.T) {
+	generalAuthenticateCommand := &osdp.GeneralAuthenticateCommand{Algorithm: osdp.PIVAlgorithmECCP256, KeyReference: osdp.PIVKeyCardAuthentication}
+	require.Equal(t, osdp.InvalidPIVAuthDataError, generalAuthenticateCommand.Validate())
+	generalAuthenticateCommand.Template = make([]byte, 0xFFFE)
+	require.Equal(t, osdp.MultiPartTooLargeError, generalAuthenticateCommand.Validate())
+	challengeResponseCommand := &osdp.ChallengeResponseCommand{Algorithm: osdp.PIVAlgorithmECCP256, KeyReference: osdp.PIVKeyCardAuthentication, Challenge: make([]byte, 0xFFFD)}
+	require.Equal(t, nil, challengeResponseCommand.Validate())
+	challengeResponseCommand.Challenge = make([]byte, 0x10000)
+	require.Equal(t, osdp.MultiPartTooLargeError, challengeResponseCommand.Validate())
+}
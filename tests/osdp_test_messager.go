@@ -1,6 +1,12 @@
 package main
 
-import "time"
+import (
+	"errors"
+	"io"
+	"time"
+
+	osdp "github.com/verkada/go-osdp"
+)
 
 type MockTransceiver struct {
 	timesCalled int
@@ -38,3 +44,78 @@ func (transceiver *SlowTransceiver) Reset() error {
 func (transceiver *MockTransceiver) Reset() error {
 	return nil
 }
+
+// ScriptedTransceiver plays a PD that answers with respond. Like an OSDP 2.2 PD it acknowledges osdp_ACURXSIZE
+// itself, recording it in announcements rather than transmitted, unless scriptReceiveSize is set
+type ScriptedTransceiver struct {
+	transmitted       []*osdp.OSDPPacket
+	announcements     []*osdp.OSDPPacket
+	scriptReceiveSize bool
+	pending           []byte
+	respond           func(osdpPacket *osdp.OSDPPacket) []byte
+}
+
+func NewScriptedTransceiver(respond func(osdpPacket *osdp.OSDPPacket) []byte) *ScriptedTransceiver {
+	return &ScriptedTransceiver{respond: respond}
+}
+
+func (transceiver *ScriptedTransceiver) Transmit(payload []byte) error {
+	osdpPacket, err := osdp.NewPacketFromBytes(payload)
+	if err != nil {
+		return err
+	}
+	if osdp.OSDPCode(osdpPacket.GetMessageCode()) == osdp.CMD_ACURXSIZE && !transceiver.scriptReceiveSize {
+		transceiver.announcements = append(transceiver.announcements, osdpPacket)
+		transceiver.pending = append(transceiver.pending, replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})...)
+		return nil
+	}
+	transceiver.transmitted = append(transceiver.transmitted, osdpPacket)
+	transceiver.pending = append(transceiver.pending, transceiver.respond(osdpPacket)...)
+	return nil
+}
+
+func (transceiver *ScriptedTransceiver) Receive() ([]byte, error) {
+	if len(transceiver.pending) == 0 {
+		return nil, io.EOF
+	}
+	// Hand over one packet at a time, as a PD's replies arrive one after the other
+	payload := transceiver.pending
+	if len(payload) >= 4 {
+		if length := int(payload[2]) | int(payload[3])<<8; length > 0 && length < len(payload) {
+			payload = payload[:length]
+		}
+	}
+	transceiver.pending = transceiver.pending[len(payload):]
+	return payload, nil
+}
+
+func (transceiver *ScriptedTransceiver) Reset() error {
+	transceiver.pending = nil
+	return nil
+}
+
+func replyPacket(osdpPacket *osdp.OSDPPacket, osdpCode osdp.OSDPCode, msgData []byte) []byte {
+	reply, err := osdp.NewPacket(osdpCode, osdpPacket.GetPeripheralAddress()|0x80, msgData, osdpPacket.GetSequenceNumber(), true)
+	if err != nil {
+		return nil
+	}
+	return reply.ToBytes()
+}
+
+type BaudRateTransceiver struct {
+	*ScriptedTransceiver
+	baudRate     uint32
+	failBaudRate uint32
+}
+
+func (transceiver *BaudRateTransceiver) BaudRate() uint32 {
+	return transceiver.baudRate
+}
+
+func (transceiver *BaudRateTransceiver) SetBaudRate(baudRate uint32) error {
+	if baudRate == transceiver.failBaudRate {
+		return errors.New("Baud Rate not supported")
+	}
+	transceiver.baudRate = baudRate
+	return nil
+}
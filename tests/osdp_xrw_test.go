@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	osdp "github.com/verkada/go-osdp"
+)
+
+func TestTransparentAPDUExchange(t *testing.T) {
+	selectAPDU := []byte{0x00, 0xA4, 0x04, 0x00, 0x05, 0xA0, 0x00, 0x00, 0x03, 0x08, 0x00}
+	cardPresentPolls := 2
+	transceiver := NewScriptedTransceiver(func(osdpPacket *osdp.OSDPPacket) []byte {
+		msgData := osdpPacket.GetMessageData()
+		switch osdp.OSDPCode(osdpPacket.GetMessageCode()) {
+		case osdp.CMD_XWR:
+			switch {
+			case msgData[0] == 0x00 && msgData[1] == osdp.XWRSetModeSetting:
+				return replyPacket(osdpPacket, osdp.REPLY_XRD, []byte{0x00, osdp.XRDModeSettingReport, msgData[2], msgData[3]})
+			case msgData[0] == 0x01 && msgData[1] == osdp.XWRTransparentSend:
+				return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+			case msgData[0] == 0x01 && msgData[1] == osdp.XWRConnectionDone:
+				return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+			}
+		case osdp.CMD_POLL:
+			if cardPresentPolls > 0 {
+				cardPresentPolls--
+				if cardPresentPolls == 0 {
+					return replyPacket(osdpPacket, osdp.REPLY_XRD, []byte{0x01, osdp.XRDCardPresent, 0x00, 0x01})
+				}
+				return replyPacket(osdpPacket, osdp.REPLY_ACK, []byte{})
+			}
+			return replyPacket(osdpPacket, osdp.REPLY_XRD, []byte{0x01, osdp.XRDTransparentData, 0x00, 0x00, 0x6F, 0x10, 0x90, 0x00})
+		}
+		return nil
+	})
+	messenger := osdp.NewOSDPMessenger(transceiver, false)
+
+	err := messenger.SetTransparentMode(context.Background(), 0x01, osdp.TransparentModeExtended, false)
+	if err != nil {
+		t.Errorf("Unable to Enable Transparent Mode: %v", err)
+		return
+	}
+	require.Equal(t, []byte{0x00, 0x02, 0x01, 0x00}, transceiver.transmitted[0].GetMessageData())
+
+	cardPresent, err := messenger.WaitForCard(context.Background(), 0x01)
+	if err != nil {
+		t.Errorf("Unable to Wait for Card: %v", err)
+		return
+	}
+	require.Equal(t, &osdp.TransparentCardPresent{ReaderNumber: 0x00, Status: 0x01}, cardPresent)
+
+	response, err := messenger.SendAPDU(context.Background(), 0x01, 0x00, selectAPDU)
+	if err != nil {
+		t.Errorf("Unable to Send APDU: %v", err)
+		return
+	}
+	require.Equal(t, append([]byte{0x01, 0x01, 0x00}, selectAPDU...), transceiver.transmitted[3].GetMessageData())
+	require.Equal(t, []byte{0x6F, 0x10, 0x90, 0x00}, response.Data)
+
+	err = messenger.TransparentDone(context.Background(), 0x01, 0x00)
+	require.Equal(t, nil, err)
+
+	_, err = messenger.SendAPDU(context.Background(), 0x01, 0x00, []byte{0x00})
+	require.Equal(t, osdp.InvalidAPDUError, err)
+}
+
+func TestParseTransparentCardInfo(t *testing.T) {
+	extendedReadReply, err := osdp.ParseExtendedReadReply([]byte{0x00, 0x02, 0x01, 0x04, 0x04, 0x02, 0x04, 0x6A, 0x2B, 0x91, 0x44, 0x00})
+	if err != nil {
+		t.Errorf("Unable to Parse Extended Read Reply: %v", err)
+		return
+	}
+	cardInfo, err := osdp.ParseTransparentCardInfo(extendedReadReply)
+	if err != nil {
+		t.Errorf("Unable to Parse Card Info: %v", err)
+		return
+	}
+	require.Equal(t, &osdp.TransparentCardInfo{ReaderNumber: 0x01, Protocol: 0x04, CSN: []byte{0x04, 0x6A, 0x2B, 0x91}, ProtocolData: []byte{0x44, 0x00}}, cardInfo)
+
+	_, err = osdp.ParseTransparentCardPresent(extendedReadReply)
+	require.Equal(t, osdp.UnexpectedReplyError, err)
+}